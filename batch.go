@@ -0,0 +1,149 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+//region InsertMany
+
+// Добавляет все записи из среза items пачками INSERT ... VALUES (...),(...), размер пачки ограничен количеством аргументов диалекта (или SetMaxParams)
+// Если диалект поддерживает RETURNING и указан queryConfig.Returning, ColumnName или столбец с опцией auto, то возвращаются значения ReturningColumn в порядке добавления (0, если он не возвращается)
+// Столбцы Returning записываются в элементы items, которые являются указателями на структуры
+// Пачки выполняются отдельными запросами, для атомарности используйте транзакцию
+// ======================================================================================
+// Inserts all records of the items slice in batches of INSERT ... VALUES (...),(...), the batch size is limited by the dialect's number of arguments (or SetMaxParams)
+// If the dialect supports RETURNING and queryConfig.Returning, ColumnName or the column with the auto option is specified, the ReturningColumn values are returned in insertion order (0 if it is not returned)
+// The Returning columns are written into the items that are pointers to structs
+// Batches are executed as separate queries, use a transaction for atomicity
+func (db *DB) InsertMany(queryConfig sqlstrings.QueryConfig, items any) ([]int, error) {
+	return db.InsertManyContext(context.Background(), queryConfig, items)
+}
+
+func (db *DB) InsertManyContext(context context.Context, queryConfig sqlstrings.QueryConfig, items any) ([]int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.insertManyWith(context, db.handler, queryConfig, items)
+}
+
+func (tx *Tx) InsertMany(queryConfig sqlstrings.QueryConfig, items any) ([]int, error) {
+	return tx.InsertManyContext(context.Background(), queryConfig, items)
+}
+
+func (tx *Tx) InsertManyContext(context context.Context, queryConfig sqlstrings.QueryConfig, items any) ([]int, error) {
+	return tx.db.insertManyWith(context, tx.handler, queryConfig, items)
+}
+
+// Ограничивает количество аргументов в одном запросе InsertMany и Preload, 0 - использовать ограничение диалекта
+// ======================================================================================
+// Limits the number of arguments in a single InsertMany and Preload query, 0 - use the dialect's limit
+func (db *DB) SetMaxParams(maxParams int) {
+	db.maxParams.Store(int64(maxParams))
+}
+
+// Ограничение количества аргументов одного запроса: SetMaxParams или ограничение диалекта
+func (db *DB) paramsLimit(dialect sqlstrings.Dialect) int {
+	if maxParams := int(db.maxParams.Load()); maxParams > 0 {
+		return maxParams
+	}
+	return dialect.MaxParams()
+}
+
+func (db *DB) insertManyWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, items any) ([]int, error) {
+	queryConfig = db.prepareConfig(queryConfig)
+	queryConfig.QueryType = sqlstrings.INSERT
+	context = withOperation(context, OPINSERT)
+
+	itemsVal := reflect.ValueOf(items)
+	for itemsVal.Kind() == reflect.Pointer {
+		itemsVal = itemsVal.Elem()
+	}
+
+	if itemsVal.Kind() != reflect.Slice && itemsVal.Kind() != reflect.Array {
+		return nil, errors.New("items must be a slice")
+	}
+
+	count := itemsVal.Len()
+	if count == 0 {
+		return nil, nil
+	}
+
+	// nil элементы проверяются до первой пачки, чтобы не добавить записи частично
+	for i := range count {
+		item := itemsVal.Index(i)
+		if (item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface) && item.IsNil() {
+			return nil, fmt.Errorf("%w: items[%d]", ErrNilItem, i)
+		}
+	}
+
+	queryConfig.Item = itemsVal.Index(0).Interface()
+
+	typeMap, err := db.typeMapper().Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	perRow := len(sqlreflect.GetInsertManyValuesOfItem(queryConfig, typeMap))
+	if perRow == 0 {
+		return nil, errors.New("item has no columns to insert")
+	}
+
+	batchSize := max(1, db.paramsLimit(queryConfig.Dialect)/perRow)
+	if maxRows := queryConfig.Dialect.MaxInsertRows(); maxRows > 0 {
+		batchSize = min(batchSize, maxRows)
+	}
+
+	returning := len(queryConfig.ReturningColumns()) > 0 && queryConfig.Dialect.SupportsReturning()
+
+	var rowsHandler RowsHandler
+	if returning {
+		var ok bool
+		if rowsHandler, ok = handler.(RowsHandler); !ok {
+			return nil, errors.New("handler does not support row iteration")
+		}
+	}
+
+	var ids []int
+
+	for start := 0; start < count; start += batchSize {
+		end := min(start+batchSize, count)
+
+		args := make([]any, 0, (end-start)*perRow)
+		for i := start; i < end; i++ {
+			itemConfig := queryConfig
+			itemConfig.Item = itemsVal.Index(i).Interface()
+			args = append(args, sqlreflect.GetInsertManyValuesOfItem(itemConfig, typeMap)...)
+		}
+
+		query := sqlstrings.GetInsertManyQuery(queryConfig, end-start)
+
+		if !returning {
+			if _, err := handler.ExecContext(context, query, queryConfig, args...); err != nil {
+				return ids, wrapQueryError(err, query, queryConfig)
+			}
+			continue
+		}
+
+		// возвращенные столбцы записываются в элементы-указатели на структуры
+		batchIds, err := db.queryReturning(context, rowsHandler, query, queryConfig, func(row int) reflect.Value {
+			if start+row < end {
+				return itemsVal.Index(start + row)
+			}
+			return reflect.Value{}
+		}, args...)
+		ids = append(ids, batchIds...)
+		if err != nil {
+			return ids, err
+		}
+	}
+
+	return ids, nil
+}
+
+//endregion
@@ -0,0 +1,76 @@
+package gosql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+func TestInsertMany(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{}
+	db.ChangeHandler(handler)
+	db.SetMaxParams(4)
+
+	items := []Users{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db", ExcludedTags: []string{"Id"}}
+
+	ids, err := db.InsertMany(qc, items)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ids != nil {
+		t.Errorf("ids must be returned only when ColumnName is specified")
+	}
+
+	expected := []string{
+		"INSERT INTO users (Name) VALUES ($1),($2),($3),($4)",
+		"INSERT INTO users (Name) VALUES ($1)",
+	}
+
+	if len(handler.queries) != len(expected) {
+		t.Fatalf("wrong number of batches: %v", handler.queries)
+	}
+
+	for i := range expected {
+		if handler.queries[i] != expected[i] {
+			t.Errorf("QUERIES NOT MATCH\n%s\n%s", expected[i], handler.queries[i])
+		}
+	}
+
+	if len(handler.args[0]) != 4 || handler.args[0][3] != "d" || handler.args[1][0] != "e" {
+		t.Errorf("wrong batch args: %v", handler.args)
+	}
+
+	handler.queries, handler.args = nil, nil
+	handler.rows = [][]any{{10}, {11}}
+
+	ids, err = db.InsertMany(qc.ChangeColumnName("Id"), items[:2])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
+		t.Errorf("ids not match: %v", ids)
+	}
+
+	if handler.queries[0] != "INSERT INTO users (Name) VALUES ($1),($2) RETURNING Id" {
+		t.Errorf("wrong returning query: %s", handler.queries[0])
+	}
+
+	// nil элемент отклоняется до выполнения запросов
+	handler.queries = nil
+	if _, err := db.InsertMany(qc, []*Users{{Name: "a"}, nil}); !errors.Is(err, ErrNilItem) || !strings.Contains(err.Error(), "items[1]") {
+		t.Errorf("expected ErrNilItem with the index, got %v", err)
+	}
+	if _, err := db.InsertMany(qc, []*Users{nil}); !errors.Is(err, ErrNilItem) {
+		t.Errorf("expected ErrNilItem, got %v", err)
+	}
+	if len(handler.queries) != 0 {
+		t.Errorf("no query must be executed: %v", handler.queries)
+	}
+}
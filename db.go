@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -24,10 +25,21 @@ type DbHandler interface {
 type DB struct {
 	Id             string
 	handler        DbHandler
+	base           DbHandler
+	middlewares    []Middleware
+	logHook        *LogHook
+	logMutex       sync.Mutex
+	traceHook      traceHook
+	traceOnce      sync.Once
+	metricsHook    metricsHook
+	metricsOnce    sync.Once
+	retries        atomic.Pointer[RetryPolicy]
 	handlerMutex   sync.RWMutex
 	useCachedFuncs *atomic.Bool
 	mapper         *sqlreflect.Mapper
 	mapperMutex    sync.RWMutex
+	dialect        atomic.Int32
+	maxParams      atomic.Int64
 }
 
 type StdDbHandler struct {
@@ -39,39 +51,104 @@ type StdDbHandler struct {
 
 //region StdDbHandler Realization
 
-func (stdh *StdDbHandler) SelectContext(context context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
-
-	stdh.dbMutex.RLock()
-	rows, err := stdh.db.QueryContext(context, query, args...)
-	stdh.dbMutex.RUnlock()
+// Общий интерфейс для *sql.DB и *sql.Tx
+// ======================================================================================
+// Common interface for *sql.DB and *sql.Tx
+type sqlConn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
 
+func selectConn(context context.Context, conn sqlConn, scanner sqlreflect.Scanner, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	rows, err := conn.QueryContext(context, query, args...)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	stdh.scannerMutex.RLock()
-	defer stdh.scannerMutex.RUnlock()
-	err = stdh.scanner.Scan(dest, rows, queryConfig)
+	return scanner.Scan(dest, rows, queryConfig)
+}
 
-	return err
+// Если диалект не поддерживает RETURNING, Id берется из sql.Result.LastInsertId
+// Если сгенерированному запросу нечего возвращать, возвращается LastInsertId, когда драйвер его поддерживает, иначе 0
+// Запрос без queryConfig.Item считается написанным вручную и читается через QueryRow
+// ======================================================================================
+// If the dialect does not support RETURNING, the Id is taken from sql.Result.LastInsertId
+// If the generated query has nothing to return, LastInsertId is returned when the driver supports it, otherwise 0
+// A query without queryConfig.Item is considered handwritten and is read via QueryRow
+func insertConn(context context.Context, conn sqlConn, query string, queryConfig sqlstrings.QueryConfig, args ...any) (id int, err error) {
+	returning := queryConfig.Dialect.SupportsReturning()
+	if returning && (queryConfig.Item == nil || len(queryConfig.ReturningColumns()) > 0) {
+		err = conn.QueryRowContext(context, query, args...).Scan(&id)
+		return id, err
+	}
+
+	res, err := conn.ExecContext(context, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil && returning {
+		return 0, nil
+	}
+
+	return int(lastId), err
 }
 
-func (stdh *StdDbHandler) InsertContext(context context.Context, query string, y_ sqlstrings.QueryConfig, args ...any) (id int, err error) {
+func execConn(context context.Context, conn sqlConn, query string, args ...any) (int, error) {
+	res, err := conn.ExecContext(context, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	aff, err := res.RowsAffected()
+	return (int)(aff), err
+}
 
+func (stdh *StdDbHandler) SelectContext(context context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
 	stdh.dbMutex.RLock()
 	defer stdh.dbMutex.RUnlock()
-	err = stdh.db.QueryRowContext(context, query, args...).Scan(&id)
 
-	return id, err
+	stdh.scannerMutex.RLock()
+	defer stdh.scannerMutex.RUnlock()
+
+	return selectConn(context, stdh.db, stdh.scanner, dest, query, queryConfig, args...)
+}
+
+func (stdh *StdDbHandler) InsertContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (id int, err error) {
+	stdh.dbMutex.RLock()
+	defer stdh.dbMutex.RUnlock()
 
+	return insertConn(context, stdh.db, query, queryConfig, args...)
 }
 
 func (stdh *StdDbHandler) ExecContext(context context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (int, error) {
 	stdh.dbMutex.RLock()
 	defer stdh.dbMutex.RUnlock()
-	res, err := stdh.db.ExecContext(context, query, args...)
-	aff, _ := res.RowsAffected()
-	return (int)(aff), err
+
+	return execConn(context, stdh.db, query, args...)
+}
+
+// Начинает транзакцию на текущем sql.DB, сканер наследуется от обработчика
+// ======================================================================================
+// Begins a transaction on the current sql.DB, the scanner is inherited from the handler
+func (stdh *StdDbHandler) BeginTxHandler(context context.Context, opts *sql.TxOptions) (TxHandler, error) {
+	stdh.dbMutex.RLock()
+	tx, err := stdh.db.BeginTx(context, opts)
+	stdh.dbMutex.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stdh.scannerMutex.RLock()
+	defer stdh.scannerMutex.RUnlock()
+
+	return &StdTxHandler{
+		tx:      tx,
+		scanner: stdh.scanner,
+	}, nil
 }
 
 // dest должен быть указателем на slice
@@ -97,6 +174,12 @@ func (stdh *StdDbHandler) ChangeScanner(sc sqlreflect.Scanner) {
 	stdh.scanner = sc
 }
 
+func (stdh *StdDbHandler) Scanner() sqlreflect.Scanner {
+	stdh.scannerMutex.RLock()
+	defer stdh.scannerMutex.RUnlock()
+	return stdh.scanner
+}
+
 // Этот метод блокирует вызывающую горутину пока db не станет доступна для записи
 // ======================================================================================
 // This method blocks the calling goroutine until the db becomes writable.
@@ -130,12 +213,14 @@ func GetDb(db *sql.DB, id string) *DB {
 			mapper = stds.Mapper
 		}
 	}
-	return &DB{
-		handler:        handler,
+	res := &DB{
+		base:           handler,
 		Id:             id,
 		useCachedFuncs: &atom,
 		mapper:         mapper,
 	}
+	res.handler = res.retryWrap(handler)
+	return res
 }
 
 func (db *DB) SetMapper(mapper *sqlreflect.Mapper) {
@@ -158,7 +243,7 @@ func (db *DB) SelectQueryContext(context context.Context, query string, queryCon
 	db.handlerMutex.RLock()
 	defer db.handlerMutex.RUnlock()
 
-	return db.handler.SelectContext(context, dest, query, queryConfig, args...)
+	return db.selectQueryWith(context, db.handler, query, queryConfig, dest, args...)
 }
 
 // dest должен быть указателем на slice
@@ -175,14 +260,7 @@ func (db *DB) SelectContext(context context.Context, queryConfig sqlstrings.Quer
 	db.handlerMutex.RLock()
 	defer db.handlerMutex.RUnlock()
 
-	query := ""
-
-	if db.useCachedFuncs.Load() {
-		query = sqlstrings.GetSelectQueryCached(queryConfig)
-	} else {
-		query = sqlstrings.GetSelectQuery(queryConfig)
-	}
-	return db.handler.SelectContext(context, dest, query, queryConfig, args...)
+	return db.selectWith(context, db.handler, queryConfig, dest, args...)
 }
 
 // dest должен быть указателем на структуру
@@ -196,52 +274,200 @@ func (db *DB) Get(queryConfig sqlstrings.QueryConfig, dest any, args ...any) err
 // ======================================================================================
 // dest should be a pointer to struct
 func (db *DB) GetContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.getWith(context, db.handler, queryConfig, dest, args...)
+}
+
+// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
+// Также при указании queryConfig.ColumnName будет произведен поиск поля структуры с значением тега равного queryConfig.ColumnName и значение этого поля будет использоваться в выражении WHERE = $1
+// ================================================================================================================================
+// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
+// Also, when queryConfig.columnName is specified, a structure field with a tag value equal to queryConfig.columnName will be searched and the value of this field will be used in the expression WHERE = $1.
+func (db *DB) Insert(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return db.InsertContext(context.Background(), queryConfig, args...)
+}
+
+// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
+// ================================================================================================================================
+// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
+func (db *DB) InsertContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.insertWith(context, db.handler, queryConfig, args...)
+}
+
+// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
+// Возвращает количество затронутых строк и оставлен для совместимости, полный sql.Result (RowsAffected и LastInsertId) возвращает UpdateResult
+// ================================================================================================================================
+// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
+// Returns the number of affected rows and is kept for compatibility, the full sql.Result (RowsAffected and LastInsertId) is returned by UpdateResult
+func (db *DB) Update(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return db.UpdateContext(context.Background(), queryConfig, args...)
+}
+
+// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
+// ================================================================================================================================
+// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
+func (db *DB) UpdateContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.updateWith(context, db.handler, queryConfig, args...)
+}
+
+// Возвращает количество затронутых строк и оставлен для совместимости, полный sql.Result (RowsAffected и LastInsertId) возвращает DeleteResult
+// ======================================================================================
+// Returns the number of affected rows and is kept for compatibility, the full sql.Result (RowsAffected and LastInsertId) is returned by DeleteResult
+func (db *DB) Delete(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return db.DeleteContext(context.Background(), queryConfig, args...)
+}
+
+func (db *DB) DeleteContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.deleteWith(context, db.handler, queryConfig, args...)
+}
+
+// Возвращает количество затронутых строк и оставлен для совместимости, полный sql.Result (RowsAffected и LastInsertId) возвращает ExecResult
+// ======================================================================================
+// Returns the number of affected rows and is kept for compatibility, the full sql.Result (RowsAffected and LastInsertId) is returned by ExecResult
+func (db *DB) Exec(query string, args ...any) (int, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExecContext(context context.Context, query string, args ...any) (int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.execWith(context, db.handler, query, args...)
+}
+
+// Диалект по умолчанию, подставляется в queryConfig если в нем диалект не указан
+// ======================================================================================
+// Default dialect, it is put into queryConfig if the config does not specify a dialect
+func (db *DB) SetDialect(dialect sqlstrings.Dialect) {
+	db.dialect.Store(int32(dialect))
+}
+
+func (db *DB) Dialect() sqlstrings.Dialect {
+	return sqlstrings.Dialect(db.dialect.Load())
+}
+
+func (db *DB) UseCachedFuncs(b bool) {
+	db.useCachedFuncs.Store(b)
+}
+
+// Заменяет обработчик, добавленные через Use middleware оборачивают новый обработчик, повторы по RetryPolicy работают поверх middleware
+// ======================================================================================
+// Replaces the handler, the middlewares added via Use wrap the new handler, retries by RetryPolicy work on top of the middlewares
+func (db *DB) ChangeHandler(handler DbHandler) {
+	db.handlerMutex.Lock()
+	defer db.handlerMutex.Unlock()
+	db.base = handler
+	db.handler = db.retryWrap(handler)
+}
+
+//endregion
+
+//region DB Shared Realization
+
+// Методы ниже содержат общую логику для DB и Tx, обработчик передается явно
+// ======================================================================================
+// The methods below contain the logic shared by DB and Tx, the handler is passed explicitly
+
+func (db *DB) prepareConfig(queryConfig sqlstrings.QueryConfig) sqlstrings.QueryConfig {
+	if queryConfig.Dialect == sqlstrings.DEFAULTDIALECT {
+		queryConfig.Dialect = db.Dialect()
+	}
+	return queryConfig
+}
+
+// Возвращает строку SELECT, столбцы сортировки проверяются по тегам Item
+// ======================================================================================
+// Returns the SELECT string, sort columns are checked against the Item tags
+func (db *DB) getSelectQuery(queryConfig sqlstrings.QueryConfig) (string, error) {
+	if queryConfig.Item == nil {
+		return "", ErrNilItem
+	}
+
+	if err := sqlstrings.ValidateOrderBy(queryConfig); err != nil {
+		return "", err
+	}
+
+	if db.useCachedFuncs.Load() {
+		return sqlstrings.GetSelectQueryCached(queryConfig), nil
+	}
+
+	return sqlstrings.GetSelectQuery(queryConfig), nil
+}
+
+func (db *DB) selectQueryWith(context context.Context, handler DbHandler, query string, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	queryConfig = db.prepareConfig(queryConfig)
+	return wrapQueryError(handler.SelectContext(context, dest, query, queryConfig, args...), query, queryConfig)
+}
+
+func (db *DB) selectWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	queryConfig = db.prepareConfig(queryConfig)
+
+	query, err := db.getSelectQuery(queryConfig)
+	if err != nil {
+		return err
+	}
+
+	args = append(args, sqlstrings.WhereArgs(queryConfig)...)
 
-	if len(queryConfig.ColumnName) == 0 {
-		return errors.New("queryConfig parameter ColumnName must be specified")
+	return wrapQueryError(handler.SelectContext(context, dest, query, queryConfig, args...), query, queryConfig)
+}
+
+func (db *DB) getWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	queryConfig = db.prepareConfig(queryConfig)
+
+	if len(queryConfig.ColumnName) == 0 && queryConfig.Where == nil {
+		return errors.New("queryConfig parameter ColumnName or Where must be specified")
 	}
 
 	tdest := reflect.TypeOf(dest)
 
-	if tdest.Kind() != reflect.Pointer {
-		return errors.New("dest must be a pointer")
+	if tdest == nil || tdest.Kind() != reflect.Pointer {
+		return fmt.Errorf("%w: dest must be a pointer", ErrInvalidDest)
 	}
 
 	tstruct := tdest.Elem()
 
 	if tstruct.Kind() != reflect.Struct {
-		return errors.New("dest must be a pointer to the struct")
+		return fmt.Errorf("%w: dest must be a pointer to the struct", ErrInvalidDest)
 	}
 
 	typeSlice := reflect.SliceOf(tstruct)
 
 	slicePointer := reflect.New(typeSlice)
 
-	query := ""
-
-	if db.useCachedFuncs.Load() {
-		query = sqlstrings.GetSelectQueryCached(queryConfig)
-	} else {
-		query = sqlstrings.GetSelectQuery(queryConfig)
+	query, err := db.getSelectQuery(queryConfig)
+	if err != nil {
+		return err
 	}
 
-	db.handlerMutex.RLock()
-	err := db.handler.SelectContext(context, slicePointer.Interface(), query, queryConfig, args...)
-	db.handlerMutex.RUnlock()
+	args = append(args, sqlstrings.WhereArgs(queryConfig)...)
+
+	err = handler.SelectContext(context, slicePointer.Interface(), query, queryConfig, args...)
 
 	if err != nil {
-		return err
+		return wrapQueryError(err, query, queryConfig)
 	}
 
 	slice := slicePointer.Elem()
 
 	length := slice.Len()
 	if length == 0 {
-		return errors.New("result set is empty")
+		return ErrNoRows
 	}
 
 	if length > 1 {
-		return errors.New("result set have more than 1 element")
+		return ErrTooManyRows
 	}
 
 	val := reflect.ValueOf(dest).Elem()
@@ -252,21 +478,36 @@ func (db *DB) GetContext(context context.Context, queryConfig sqlstrings.QueryCo
 	return err
 }
 
-// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
-// Также при указании queryConfig.ColumnName будет произведен поиск поля структуры с значением тега равного queryConfig.ColumnName и значение этого поля будет использоваться в выражении WHERE = $1
-// ================================================================================================================================
-// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
-// Also, when queryConfig.columnName is specified, a structure field with a tag value equal to queryConfig.columnName will be searched and the value of this field will be used in the expression WHERE = $1.
-func (db *DB) Insert(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
-	return db.InsertContext(context.Background(), queryConfig, args...)
+func (db *DB) insertWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	queryConfig = db.prepareConfig(queryConfig)
+	context = withOperation(context, OPINSERT)
+
+	query, args, err := db.insertQueryArgs(queryConfig, args...)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(queryConfig.Returning) > 0 {
+		ids, err := db.returningWith(context, handler, query, queryConfig, args...)
+		if err != nil {
+			return -1, err
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		return ids[0], nil
+	}
+
+	id, err := handler.InsertContext(context, query, queryConfig, args...)
+
+	return id, wrapQueryError(err, query, queryConfig)
 }
 
-// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
-// ================================================================================================================================
-// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
-func (db *DB) InsertContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
-	db.handlerMutex.RLock()
-	defer db.handlerMutex.RUnlock()
+// Возвращает строку INSERT и аргументы, если аргументы не переданы они берутся из queryConfig.Item
+func (db *DB) insertQueryArgs(queryConfig sqlstrings.QueryConfig, args ...any) (string, []any, error) {
+	if queryConfig.Item == nil {
+		return "", nil, ErrNilItem
+	}
 
 	query := ""
 
@@ -281,27 +522,43 @@ func (db *DB) InsertContext(context context.Context, queryConfig sqlstrings.Quer
 		typeMap, err := db.mapper.Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
 		db.mapperMutex.RUnlock()
 		if err != nil {
-			return -1, err
+			return "", nil, err
 		}
 
 		queryConfig.QueryType = sqlstrings.INSERT
 		args = sqlreflect.GetFieldsValuesOfItem(queryConfig, typeMap)
 	}
 
-	return db.handler.InsertContext(context, query, queryConfig, args...)
+	return query, args, nil
 }
 
-// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
-// ================================================================================================================================
-// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
-func (db *DB) Update(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
-	return db.UpdateContext(context.Background(), queryConfig, args...)
+func (db *DB) updateWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	queryConfig = db.prepareConfig(queryConfig)
+	context = withOperation(context, OPUPDATE)
+
+	query, args, err := db.updateQueryArgs(queryConfig, args...)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(queryConfig.Returning) > 0 {
+		ids, err := db.returningWith(context, handler, query, queryConfig, args...)
+		if err != nil {
+			return -1, err
+		}
+		return len(ids), nil
+	}
+
+	res, err := handler.ExecContext(context, query, queryConfig, args...)
+
+	return res, wrapQueryError(err, query, queryConfig)
 }
 
-// Если аргументы пусты, маппер присутствует  и queryConfig.ItemToAdd != nil, тогда аргументы будут взяты из queryConfig.ItemToAdd, если хотите отключить такое поведение вызовите SetMapper(nil)
-// ================================================================================================================================
-// If the arguments are empty, the mapper is present and queryConfig.ItemToAdd != nil, then the arguments will be taken from queryConfig.ItemToAdd, if you want to disable this behavior, call SetMapper(nil)
-func (db *DB) UpdateContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+// Возвращает строку UPDATE и аргументы, если аргументы не переданы они берутся из queryConfig.Item, аргументы Where добавляются в конец
+func (db *DB) updateQueryArgs(queryConfig sqlstrings.QueryConfig, args ...any) (string, []any, error) {
+	if queryConfig.Item == nil {
+		return "", nil, ErrNilItem
+	}
 
 	query := ""
 
@@ -316,54 +573,51 @@ func (db *DB) UpdateContext(context context.Context, queryConfig sqlstrings.Quer
 		typeMap, err := db.mapper.Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
 		db.mapperMutex.RUnlock()
 		if err != nil {
-			return -1, err
+			return "", nil, err
 		}
 		queryConfig.QueryType = sqlstrings.UPDATE
 		args = sqlreflect.GetFieldsValuesOfItem(queryConfig, typeMap)
 	}
 
-	db.handlerMutex.RLock()
-	res, err := db.handler.ExecContext(context, query, queryConfig, args...)
-	db.handlerMutex.RUnlock()
+	args = append(args, sqlstrings.WhereArgs(queryConfig)...)
 
-	return res, err
+	return query, args, nil
 }
 
-func (db *DB) Delete(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
-	return db.DeleteContext(context.Background(), queryConfig, args...)
-}
+func (db *DB) deleteWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	queryConfig = db.prepareConfig(queryConfig)
+	context = withOperation(context, OPDELETE)
 
-func (db *DB) DeleteContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	query, args, err := deleteQueryArgs(queryConfig, args...)
+	if err != nil {
+		return -1, err
+	}
 
-	query := sqlstrings.GetDeleteQuery(queryConfig)
+	res, err := handler.ExecContext(context, query, queryConfig, args...)
 
-	db.handlerMutex.RLock()
-	defer db.handlerMutex.RUnlock()
-	res, err := db.handler.ExecContext(context, query, queryConfig, args...)
-
-	return res, err
+	return res, wrapQueryError(err, query, queryConfig)
 }
 
-func (db *DB) Exec(query string, args ...any) (int, error) {
-	return db.ExecContext(context.Background(), query, args...)
-}
+// Возвращает строку DELETE и аргументы, аргументы Where добавляются в конец
+func deleteQueryArgs(queryConfig sqlstrings.QueryConfig, args ...any) (string, []any, error) {
+	// имя таблицы берется из Item, если оно не указано
+	if queryConfig.Item == nil && len(queryConfig.TableName) == 0 {
+		return "", nil, ErrNilItem
+	}
 
-func (db *DB) ExecContext(context context.Context, query string, args ...any) (int, error) {
-	db.handlerMutex.RLock()
-	defer db.handlerMutex.RUnlock()
-	res, err := db.handler.ExecContext(context, query, sqlstrings.QueryConfig{}, args...)
+	query := sqlstrings.GetDeleteQuery(queryConfig)
 
-	return res, err
-}
+	args = append(args, sqlstrings.WhereArgs(queryConfig)...)
 
-func (db *DB) UseCachedFuncs(b bool) {
-	db.useCachedFuncs.Store(b)
+	return query, args, nil
 }
 
-func (db *DB) ChangeHandler(handler DbHandler) {
-	db.handlerMutex.Lock()
-	defer db.handlerMutex.Unlock()
-	db.handler = handler
+func (db *DB) execWith(context context.Context, handler DbHandler, query string, args ...any) (int, error) {
+	queryConfig := db.prepareConfig(sqlstrings.QueryConfig{})
+
+	res, err := handler.ExecContext(context, query, queryConfig, args...)
+
+	return res, wrapQueryError(err, query, queryConfig)
 }
 
 //endregion
@@ -0,0 +1,131 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type fakeRows struct {
+	values       [][]any
+	idx          int
+	columns      []string
+	closed       bool
+	columnsCalls int
+	err          error
+}
+
+func (r *fakeRows) Columns() ([]string, error) {
+	r.columnsCalls++
+	return r.columns, nil
+}
+
+func (r *fakeRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *fakeRows) Err() error {
+	return r.err
+}
+
+func (r *fakeRows) Next() bool {
+	r.idx++
+	return r.idx < len(r.values)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	// как и database/sql, количество приемников должно совпадать с количеством столбцов
+	if len(dest) != len(r.values[r.idx]) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(r.values[r.idx]), len(dest))
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[r.idx][i]))
+	}
+	return nil
+}
+
+type fakeHandler struct {
+	queries   []string
+	args      [][]any
+	rows      [][]any
+	rowsQueue [][][]any
+	columns   []string
+	lastRows  *fakeRows
+	committed bool
+	rolled    bool
+	execRes   int
+	err       error
+	rowsErr   error
+}
+
+func (f *fakeHandler) SelectContext(_ context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	if f.err != nil {
+		return f.err
+	}
+	// если задана очередь результатов, каждый запрос получает следующий результат
+	if len(f.rowsQueue) > 0 {
+		f.rows, f.rowsQueue = f.rowsQueue[0], f.rowsQueue[1:]
+	}
+	return sqlreflect.GetScanner().Scan(dest, &fakeRows{values: f.rows, columns: f.columns, idx: -1}, queryConfig)
+}
+
+func (f *fakeHandler) QueryContext(_ context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.lastRows = &fakeRows{values: f.rows, columns: f.columns, idx: -1, err: f.rowsErr}
+	return f.lastRows, nil
+}
+
+func (f *fakeHandler) InsertContext(_ context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (int, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	return 1, f.err
+}
+
+func (f *fakeHandler) ExecContext(_ context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (int, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	return f.execRes, f.err
+}
+
+func (f *fakeHandler) Select(dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return f.SelectContext(context.Background(), dest, query, queryConfig, args...)
+}
+
+func (f *fakeHandler) Insert(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return f.InsertContext(context.Background(), query, queryConfig, args...)
+}
+
+func (f *fakeHandler) Exec(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return f.ExecContext(context.Background(), query, queryConfig, args...)
+}
+
+func (f *fakeHandler) Commit() error {
+	f.committed = true
+	return nil
+}
+
+func (f *fakeHandler) Rollback() error {
+	f.rolled = true
+	return nil
+}
+
+type fakeBeginner struct {
+	fakeHandler
+	tx *fakeHandler
+}
+
+func (f *fakeBeginner) BeginTxHandler(_ context.Context, _ *sql.TxOptions) (TxHandler, error) {
+	f.tx = &fakeHandler{}
+	return f.tx, nil
+}
@@ -0,0 +1,70 @@
+package gosql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+//region Errors
+
+var (
+	// Get не нашел ни одной записи, errors.Is(err, sql.ErrNoRows) тоже возвращает true
+	// ======================================================================================
+	// Get found no records, errors.Is(err, sql.ErrNoRows) returns true as well
+	ErrNoRows = fmt.Errorf("result set is empty: %w", sql.ErrNoRows)
+
+	// Get нашел больше одной записи / Get found more than one record
+	ErrTooManyRows = errors.New("result set have more than 1 element")
+
+	// dest имеет неподходящий тип / dest has an unsuitable type
+	ErrInvalidDest = sqlreflect.ErrInvalidDest
+
+	// тип не является структурой или указателем на структуру / the type is neither a struct nor a pointer to the struct
+	ErrUnmappableType = sqlreflect.ErrUnmappableType
+
+	// queryConfig.Item не указан, а запрос без него сгенерировать нельзя / queryConfig.Item is not specified and the query cannot be generated without it
+	ErrNilItem = errors.New("queryConfig parameter Item must be specified")
+)
+
+// Ошибка выполнения запроса, содержит строку запроса, конфигурацию и ошибку драйвера, которую возвращает Unwrap
+// ======================================================================================
+// Query execution error, it contains the query string, the configuration and the driver error that is returned by Unwrap
+type QueryError struct {
+	Query  string
+	Config sqlstrings.QueryConfig
+	Err    error
+}
+
+func (e *QueryError) Error() string {
+	return e.Err.Error() + " (query: " + e.Query + ")"
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Оборачивает ошибку обработчика в QueryError, nil и уже обернутые ошибки возвращаются как есть
+// ======================================================================================
+// Wraps the handler error into QueryError, nil and already wrapped errors are returned as is
+func wrapQueryError(err error, query string, queryConfig sqlstrings.QueryConfig) error {
+	if err == nil {
+		return nil
+	}
+
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return err
+	}
+
+	return &QueryError{
+		Query:  query,
+		Config: queryConfig,
+		Err:    err,
+	}
+}
+
+//endregion
@@ -0,0 +1,55 @@
+package gosql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+func TestErrors(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db", Item: Users{}, ColumnName: "Id"}
+
+	var user Users
+	err := db.Get(qc, &user, 1)
+	if !errors.Is(err, ErrNoRows) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected ErrNoRows, got %v", err)
+	}
+
+	handler.rows = [][]any{{1, "first"}, {2, "second"}}
+	if err := db.Get(qc, &user, 1); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("expected ErrTooManyRows, got %v", err)
+	}
+
+	if err := db.Get(qc, user, 1); !errors.Is(err, ErrInvalidDest) {
+		t.Errorf("expected ErrInvalidDest, got %v", err)
+	}
+
+	var ints []int
+	if err := db.Select(qc, &ints); !errors.Is(err, ErrUnmappableType) {
+		t.Errorf("expected ErrUnmappableType, got %v", err)
+	}
+
+	if _, err := db.Insert(sqlstrings.QueryConfig{TableName: "Users"}); !errors.Is(err, ErrNilItem) {
+		t.Errorf("expected ErrNilItem, got %v", err)
+	}
+
+	driverErr := errors.New("connection refused")
+	handler.err = driverErr
+
+	_, err = db.Update(qc, 1, "name")
+
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) || !errors.Is(err, driverErr) {
+		t.Fatalf("expected QueryError, got %v", err)
+	}
+
+	if queryErr.Query != "UPDATE Users SET Id = $2, Name = $3 WHERE Id = $1" || queryErr.Config.ColumnName != "Id" {
+		t.Errorf("QueryError failed: %#v", queryErr)
+	}
+}
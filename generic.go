@@ -0,0 +1,130 @@
+package gosql
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Общий набор методов DB и Tx, позволяет писать код не зависящий от того выполняется ли он в транзакции
+// ======================================================================================
+// Common set of methods of DB and Tx, allows to write code that does not depend on whether it runs in a transaction
+type Querier interface {
+	SelectQueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error
+	SelectContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error
+	GetContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error
+	InsertContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error)
+	InsertScanContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error
+	UpdateContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error)
+	DeleteContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error)
+	ExecContext(context context.Context, query string, args ...any) (int, error)
+	IterateContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error]
+	IterateQueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error]
+}
+
+var (
+	_ Querier = (*DB)(nil)
+	_ Querier = (*Tx)(nil)
+)
+
+//region Generic Funcs
+
+// Ограничения типов Go не позволяют требовать, чтобы T был структурой, поэтому например SelectT[int] компилируется,
+// но до выполнения запроса возвращает ошибку ErrUnmappableType. Форму dest (срез, указатель) гарантирует компилятор, а вид T проверяется при вызове
+// ======================================================================================
+// Go type constraints cannot require T to be a struct, so for example SelectT[int] compiles,
+// but returns the ErrUnmappableType error before the query is executed. The dest shape (slice, pointer) is guaranteed by the compiler, while the kind of T is checked at call time
+
+// Возвращает все записи типа T, если queryConfig.Item не указан то используется нулевое значение T, если не указан TableName то имя типа T
+// ======================================================================================
+// Returns all records of type T, if queryConfig.Item is not specified the zero value of T is used, if TableName is not specified the name of type T is used
+func SelectT[T any](context context.Context, q Querier, queryConfig sqlstrings.QueryConfig, args ...any) ([]T, error) {
+	var res []T
+
+	queryConfig, err := typedConfig[T](queryConfig)
+	if err != nil {
+		return res, err
+	}
+
+	err = q.SelectContext(context, queryConfig, &res, args...)
+
+	return res, err
+}
+
+// Возвращает одну запись типа T, T может быть структурой или указателем на структуру, queryConfig.ColumnName или queryConfig.Where должен быть указан
+// ======================================================================================
+// Returns a single record of type T, T can be a struct or a pointer to the struct, queryConfig.ColumnName or queryConfig.Where must be specified
+func GetT[T any](context context.Context, q Querier, queryConfig sqlstrings.QueryConfig, args ...any) (T, error) {
+	var res T
+
+	queryConfig, err := typedConfig[T](queryConfig)
+	if err != nil {
+		return res, err
+	}
+
+	t := reflect.TypeFor[T]()
+
+	if t.Kind() != reflect.Pointer {
+		err := q.GetContext(context, queryConfig, &res, args...)
+		return res, err
+	}
+
+	val := reflect.New(sqlreflect.ConversionTypeToNonRefType(t))
+	if err := q.GetContext(context, queryConfig, val.Interface(), args...); err != nil {
+		return res, err
+	}
+
+	ogVal, err := sqlreflect.ConversionToOgType(val.Elem().Interface(), t)
+	if err != nil {
+		return res, err
+	}
+
+	return ogVal.(T), nil
+}
+
+// Добавляет item, аргументы берутся из item, возвращает значение столбца ColumnName
+// ======================================================================================
+// Inserts item, the arguments are taken from item, returns the value of the ColumnName column
+func InsertT[T any](context context.Context, q Querier, queryConfig sqlstrings.QueryConfig, item T) (int, error) {
+	queryConfig.Item = item
+
+	queryConfig, err := typedConfig[T](queryConfig)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.InsertContext(context, queryConfig)
+}
+
+// Добавляет запись и возвращает значение ключа типа K (UUID, string, int64 ...) из первого столбца Returning, столбца ColumnName или столбца с опцией auto
+// ======================================================================================
+// Inserts a record and returns the key value of type K (UUID, string, int64 ...) from the first Returning column, the ColumnName column or the column with the auto option
+func InsertReturning[K any](context context.Context, q Querier, queryConfig sqlstrings.QueryConfig, args ...any) (K, error) {
+	var key K
+	err := q.InsertScanContext(context, queryConfig, &key, args...)
+
+	return key, err
+}
+
+func typedConfig[T any](queryConfig sqlstrings.QueryConfig) (sqlstrings.QueryConfig, error) {
+	nonRefType := sqlreflect.ConversionTypeToNonRefType(reflect.TypeFor[T]())
+	if nonRefType.Kind() != reflect.Struct {
+		return queryConfig, fmt.Errorf("%w: %s", ErrUnmappableType, reflect.TypeFor[T]())
+	}
+
+	if queryConfig.Item == nil {
+		queryConfig.Item = reflect.Zero(nonRefType).Interface()
+	}
+
+	if len(queryConfig.TableName) == 0 {
+		queryConfig.TableName = nonRefType.Name()
+	}
+
+	return queryConfig, nil
+}
+
+//endregion
@@ -0,0 +1,89 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type Users struct {
+	Id   int    `db:"Id"`
+	Name string `db:"Name"`
+}
+
+func TestGenericFuncs(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rows: [][]any{{1, "first"}, {2, "second"}}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db"}
+
+	users, err := SelectT[Users](context.Background(), db, qc)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if len(users) != 2 || users[1].Name != "second" {
+		t.Errorf("SelectT failed: %#v", users)
+	}
+
+	if handler.queries[0] != "SELECT Id, Name FROM Users" {
+		t.Errorf("table name must be derived from T: %s", handler.queries[0])
+	}
+
+	handler.rows = handler.rows[:1]
+
+	user, err := GetT[*Users](context.Background(), db, qc.ChangeColumnName("Id"), 1)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if user == nil || user.Name != "first" {
+		t.Errorf("GetT failed: %#v", user)
+	}
+
+	_, err = InsertT(context.Background(), db, qc.ChangeExcludedTags("Id"), Users{Name: "third"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	last := len(handler.queries) - 1
+	if handler.queries[last] != "INSERT INTO Users (Name) VALUES ($1)" {
+		t.Errorf("InsertT query failed: %s", handler.queries[last])
+	}
+
+	if len(handler.args[last]) != 1 || handler.args[last][0] != "third" {
+		t.Errorf("InsertT args failed: %#v", handler.args[last])
+	}
+}
+
+func TestGenericShape(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{}
+	db.ChangeHandler(handler)
+
+	// T не структура: ошибка возвращается до выполнения запроса
+	if _, err := SelectT[int](context.Background(), db, sqlstrings.QueryConfig{}); !errors.Is(err, ErrUnmappableType) {
+		t.Errorf("expected ErrUnmappableType, got %v", err)
+	}
+
+	if _, err := GetT[*string](context.Background(), db, sqlstrings.QueryConfig{ColumnName: "Id"}, 1); !errors.Is(err, ErrUnmappableType) {
+		t.Errorf("expected ErrUnmappableType, got %v", err)
+	}
+
+	if _, err := InsertT(context.Background(), db, sqlstrings.QueryConfig{}, 5); !errors.Is(err, ErrUnmappableType) {
+		t.Errorf("expected ErrUnmappableType, got %v", err)
+	}
+
+	for _, err := range Rows[[]byte](context.Background(), db, sqlstrings.QueryConfig{}) {
+		if !errors.Is(err, ErrUnmappableType) {
+			t.Errorf("expected ErrUnmappableType, got %v", err)
+		}
+	}
+
+	if len(handler.queries) != 0 {
+		t.Errorf("queries must not be executed: %v", handler.queries)
+	}
+}
@@ -0,0 +1,400 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Декоратор обработчика, получает следующий обработчик цепочки и возвращает обертку над ним
+// Если обертка не реализует RowsHandler или ResultHandler, а next реализует, эти запросы передаются next напрямую, минуя обертку
+// Чтобы перехватывать их, встройте HandlerWrapper и переопределите QueryContext и ExecResultContext
+// ======================================================================================
+// Handler decorator, it receives the next handler of the chain and returns a wrapper around it
+// If the wrapper does not implement RowsHandler or ResultHandler while next does, these queries are passed to next directly, bypassing the wrapper
+// To intercept them embed HandlerWrapper and override QueryContext and ExecResultContext
+type Middleware func(next DbHandler) DbHandler
+
+// Вид операции DB, которая выполняет запрос / Kind of the DB operation that executes the query
+type Operation int
+
+const (
+	OPSELECT Operation = iota
+	OPINSERT
+	OPUPDATE
+	OPDELETE
+	OPUPSERT
+	OPEXEC
+)
+
+func (o Operation) String() string {
+	switch o {
+	case OPSELECT:
+		return "select"
+	case OPINSERT:
+		return "insert"
+	case OPUPDATE:
+		return "update"
+	case OPDELETE:
+		return "delete"
+	case OPUPSERT:
+		return "upsert"
+	}
+	return "exec"
+}
+
+// Operation - вид операции ;
+// Query - строка запроса ;
+// Args - аргументы запроса ;
+// Config - конфигурация запроса, для запросов без конфигурации (Exec, SelectQuery) содержит только Dialect ;
+// DbId - DB.Id ;
+// Rows - количество затронутых (Exec, Insert) или полученных (Select) строк, заполняется перед After, -1 если неизвестно, для строк QueryContext (Iterate, RETURNING) After вызывается при их закрытии ;
+// ======================================================================================
+// Operation - kind of the operation ;
+// Query - query string ;
+// Args - query arguments ;
+// Config - query configuration, for queries without configuration (Exec, SelectQuery) it contains only Dialect ;
+// DbId - DB.Id ;
+// Rows - number of affected (Exec, Insert) or received (Select) rows, it is filled before After, -1 if unknown, for QueryContext rows (Iterate, RETURNING) After is called when they are closed ;
+type QueryEvent struct {
+	Operation Operation
+	Query     string
+	Args      []any
+	Config    sqlstrings.QueryConfig
+	DbId      string
+	Rows      int
+}
+
+// Хук вызывается вокруг каждого обращения к обработчику, контекст, который возвращает Before, передается обработчику и After
+// ======================================================================================
+// The hook is called around every call to the handler, the context returned by Before is passed to the handler and to After
+type QueryHook interface {
+	Before(context context.Context, event *QueryEvent) context.Context
+	After(context context.Context, event *QueryEvent, err error, duration time.Duration)
+}
+
+type operationKey struct{}
+
+// Возвращает вид операции DB, которая выполняет запрос, для middleware / Returns the kind of the DB operation that executes the query, for middlewares
+func OperationFromContext(context context.Context) (Operation, bool) {
+	op, ok := context.Value(operationKey{}).(Operation)
+	return op, ok
+}
+
+func withOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+//region DB Middlewares
+
+// Добавляет middleware в цепочку, первый добавленный вызывается первым, middleware применяются и к обработчикам транзакций
+// ======================================================================================
+// Adds middlewares to the chain, the first added one is called first, middlewares are applied to transaction handlers as well
+func (db *DB) Use(middlewares ...Middleware) {
+	db.handlerMutex.Lock()
+	defer db.handlerMutex.Unlock()
+
+	db.middlewares = append(db.middlewares, middlewares...)
+	db.handler = db.retryWrap(db.base)
+}
+
+// Добавляет хуки, каждый хук оборачивает обработчик как middleware
+// ======================================================================================
+// Adds hooks, every hook wraps the handler as a middleware
+func (db *DB) AddHook(hooks ...QueryHook) {
+	for _, hook := range hooks {
+		db.Use(func(next DbHandler) DbHandler {
+			return &hookHandler{next: next, hook: hook, db: db}
+		})
+	}
+}
+
+func (db *DB) wrapHandler(handler DbHandler) DbHandler {
+	for i := len(db.middlewares) - 1; i >= 0; i-- {
+		handler = forwardOptional(db.middlewares[i](handler), handler)
+	}
+	return handler
+}
+
+// Основа для middleware, встраивается в обертку и передает DbHandler, RowsHandler и ResultHandler следующему обработчику
+// Обертка переопределяет только нужные методы, Unwrap возвращает следующий обработчик
+// ======================================================================================
+// Base for middlewares, it is embedded into the wrapper and passes DbHandler, RowsHandler and ResultHandler to the next handler
+// The wrapper overrides only the methods it needs, Unwrap returns the next handler
+type HandlerWrapper struct {
+	DbHandler
+}
+
+func (w HandlerWrapper) Unwrap() DbHandler {
+	return w.DbHandler
+}
+
+func (w HandlerWrapper) QueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	return queryNext(context, w.DbHandler, query, queryConfig, args...)
+}
+
+func (w HandlerWrapper) ExecResultContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return execResultNext(context, w.DbHandler, query, queryConfig, args...)
+}
+
+// Обертка middleware, которая не реализует RowsHandler или ResultHandler, эти запросы передаются next
+type forwardHandler struct {
+	DbHandler
+	next DbHandler
+}
+
+// Возвращает wrapped, если он реализует все необязательные интерфейсы next, иначе добавляет их передачу в next
+func forwardOptional(wrapped DbHandler, next DbHandler) DbHandler {
+	_, rows := wrapped.(RowsHandler)
+	_, nextRows := next.(RowsHandler)
+	_, result := wrapped.(ResultHandler)
+	_, nextResult := next.(ResultHandler)
+
+	if (rows || !nextRows) && (result || !nextResult) {
+		return wrapped
+	}
+	return &forwardHandler{DbHandler: wrapped, next: next}
+}
+
+func (h *forwardHandler) Unwrap() DbHandler {
+	// если обертка сама знает следующий обработчик, цепочка идет через нее
+	if _, ok := h.DbHandler.(interface{ Unwrap() DbHandler }); ok {
+		return h.DbHandler
+	}
+	return h.next
+}
+
+func (h *forwardHandler) QueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	if rowsHandler, ok := h.DbHandler.(RowsHandler); ok {
+		return rowsHandler.QueryContext(context, query, queryConfig, args...)
+	}
+	return queryNext(context, h.next, query, queryConfig, args...)
+}
+
+func (h *forwardHandler) ExecResultContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	if resultHandler, ok := h.DbHandler.(ResultHandler); ok {
+		return resultHandler.ExecResultContext(context, query, queryConfig, args...)
+	}
+	return execResultNext(context, h.next, query, queryConfig, args...)
+}
+
+// Обработчик транзакции, обернутый в middleware, Commit и Rollback вызываются у исходного обработчика
+// ======================================================================================
+// Transaction handler wrapped into middlewares, Commit and Rollback are called on the original handler
+type middlewareTxHandler struct {
+	HandlerWrapper
+	tx TxHandler
+}
+
+func (h *middlewareTxHandler) Commit() error {
+	return h.tx.Commit()
+}
+
+func (h *middlewareTxHandler) Rollback() error {
+	return h.tx.Rollback()
+}
+
+func (h *middlewareTxHandler) Unwrap() DbHandler {
+	return h.tx
+}
+
+func queryNext(context context.Context, next DbHandler, query string, queryConfig sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	rowsHandler, ok := next.(RowsHandler)
+	if !ok {
+		return nil, errors.New("handler does not support row iteration")
+	}
+	return rowsHandler.QueryContext(context, query, queryConfig, args...)
+}
+
+func execResultNext(context context.Context, next DbHandler, query string, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	if resultHandler, ok := next.(ResultHandler); ok {
+		return resultHandler.ExecResultContext(context, query, queryConfig, args...)
+	}
+
+	affected, err := next.ExecContext(context, query, queryConfig, args...)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+//endregion
+
+//region Hook Handler
+
+type hookHandler struct {
+	next DbHandler
+	hook QueryHook
+	db   *DB
+}
+
+func (h *hookHandler) Unwrap() DbHandler {
+	return h.next
+}
+
+func (h *hookHandler) run(context context.Context, fallback Operation, query string, queryConfig sqlstrings.QueryConfig, args []any, call func(context context.Context) (int, error)) error {
+	context, event, start := h.begin(context, fallback, query, queryConfig, args)
+
+	rows, err := call(context)
+	event.Rows = rows
+
+	h.hook.After(context, event, err, time.Since(start))
+
+	return err
+}
+
+// Создает событие и вызывает Before, возвращает контекст хука и время начала запроса
+func (h *hookHandler) begin(context context.Context, fallback Operation, query string, queryConfig sqlstrings.QueryConfig, args []any) (context.Context, *QueryEvent, time.Time) {
+	op, ok := OperationFromContext(context)
+	if !ok {
+		op = fallback
+	}
+
+	event := &QueryEvent{
+		Operation: op,
+		Query:     query,
+		Args:      args,
+		Config:    queryConfig,
+		DbId:      h.db.Id,
+		Rows:      -1,
+	}
+
+	context = h.hook.Before(context, event)
+
+	return context, event, time.Now()
+}
+
+func (h *hookHandler) SelectContext(ctx context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return h.run(ctx, OPSELECT, query, queryConfig, args, func(ctx context.Context) (int, error) {
+		err := h.next.SelectContext(ctx, dest, query, queryConfig, args...)
+		if val := reflect.ValueOf(dest); err == nil && val.Kind() == reflect.Pointer && val.Elem().Kind() == reflect.Slice {
+			return val.Elem().Len(), nil
+		}
+		return -1, err
+	})
+}
+
+func (h *hookHandler) InsertContext(ctx context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (id int, err error) {
+	err = h.run(ctx, OPINSERT, query, queryConfig, args, func(ctx context.Context) (int, error) {
+		id, err = h.next.InsertContext(ctx, query, queryConfig, args...)
+		if err != nil {
+			return -1, err
+		}
+		return 1, nil
+	})
+	return id, err
+}
+
+func (h *hookHandler) ExecContext(ctx context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (res int, err error) {
+	err = h.run(ctx, OPEXEC, query, queryConfig, args, func(ctx context.Context) (int, error) {
+		res, err = h.next.ExecContext(ctx, query, queryConfig, args...)
+		if err != nil {
+			return -1, err
+		}
+		return res, nil
+	})
+	return res, err
+}
+
+func (h *hookHandler) QueryContext(ctx context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	ctx, event, start := h.begin(ctx, OPSELECT, query, queryConfig, args)
+
+	rows, err := queryNext(ctx, h.next, query, queryConfig, args...)
+	if err != nil {
+		h.hook.After(ctx, event, err, time.Since(start))
+		return nil, err
+	}
+
+	// After вызывается при закрытии строк, чтобы учесть время чтения, количество строк и ошибки
+	return &hookRows{ResultRows: rows, hook: h.hook, context: ctx, event: event, start: start}, nil
+}
+
+func (h *hookHandler) ExecResultContext(ctx context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (res sql.Result, err error) {
+	err = h.run(ctx, OPEXEC, query, queryConfig, args, func(ctx context.Context) (int, error) {
+		res, err = execResultNext(ctx, h.next, query, queryConfig, args...)
+		if err != nil {
+			return -1, err
+		}
+		affected, affectedErr := res.RowsAffected()
+		if affectedErr != nil {
+			return -1, nil
+		}
+		return int(affected), nil
+	})
+	return res, err
+}
+
+// Строки, полученные через хук, After вызывается из Close с количеством прочитанных строк и первой ошибкой Next, Scan или Err
+type hookRows struct {
+	ResultRows
+	hook    QueryHook
+	context context.Context
+	event   *QueryEvent
+	start   time.Time
+	count   int
+	err     error
+	closed  bool
+}
+
+func (r *hookRows) Next() bool {
+	if r.ResultRows.Next() {
+		r.count++
+		return true
+	}
+	r.fail(r.ResultRows.Err())
+	return false
+}
+
+func (r *hookRows) Scan(dest ...any) error {
+	err := r.ResultRows.Scan(dest...)
+	r.fail(err)
+	return err
+}
+
+func (r *hookRows) Err() error {
+	err := r.ResultRows.Err()
+	r.fail(err)
+	return err
+}
+
+func (r *hookRows) Close() error {
+	err := r.ResultRows.Close()
+	if r.closed {
+		return err
+	}
+	r.closed = true
+
+	r.fail(r.ResultRows.Err())
+	r.fail(err)
+
+	r.event.Rows = r.count
+	r.hook.After(r.context, r.event, r.err, time.Since(r.start))
+
+	return err
+}
+
+// Запоминает первую ошибку
+func (r *hookRows) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (h *hookHandler) Select(dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return h.SelectContext(context.Background(), dest, query, queryConfig, args...)
+}
+
+func (h *hookHandler) Insert(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.InsertContext(context.Background(), query, queryConfig, args...)
+}
+
+func (h *hookHandler) Exec(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.ExecContext(context.Background(), query, queryConfig, args...)
+}
+
+//endregion
@@ -0,0 +1,229 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type hookKey struct{}
+
+type recordingHook struct {
+	events []QueryEvent
+	errs   []error
+}
+
+func (h *recordingHook) Before(ctx context.Context, event *QueryEvent) context.Context {
+	return context.WithValue(ctx, hookKey{}, event.Query)
+}
+
+func (h *recordingHook) After(ctx context.Context, event *QueryEvent, err error, _ time.Duration) {
+	if ctx.Value(hookKey{}) != event.Query {
+		panic("context of Before is not passed to After")
+	}
+	h.events = append(h.events, *event)
+	h.errs = append(h.errs, err)
+}
+
+// middleware, записывающий порядок вызова
+type orderHandler struct {
+	DbHandler
+	name  string
+	order *[]string
+}
+
+func (h *orderHandler) ExecContext(ctx context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	*h.order = append(*h.order, h.name)
+	return h.DbHandler.ExecContext(ctx, query, queryConfig, args...)
+}
+
+func TestHooks(t *testing.T) {
+	db := GetDb(nil, "main")
+	beginner := &fakeBeginner{}
+	beginner.rows = [][]any{{1, "bob"}, {2, "alice"}}
+	beginner.execRes = 2
+
+	hook := &recordingHook{}
+	var order []string
+
+	db.Use(func(next DbHandler) DbHandler { return &orderHandler{DbHandler: next, name: "first", order: &order} })
+	db.AddHook(hook)
+	db.Use(func(next DbHandler) DbHandler { return &orderHandler{DbHandler: next, name: "second", order: &order} })
+
+	// middleware оборачивают и обработчик, установленный после Use
+	db.ChangeHandler(beginner)
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db", ColumnName: "Id"}
+
+	var users []txUser
+	if err := db.Select(qc.ChangeItem(txUser{}).ChangeColumnName(""), &users); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := db.Update(qc.ChangeItem(txUser{Id: 1, Name: "bob"})); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := db.Exec("DELETE FROM users"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err := db.WithTx(context.Background(), func(tx *Tx) error {
+		_, err := tx.Delete(qc.ChangeItem(txUser{}), 1)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(hook.events) != 4 {
+		t.Fatalf("events not recorded %v", hook.events)
+	}
+
+	ops := []Operation{hook.events[0].Operation, hook.events[1].Operation, hook.events[2].Operation, hook.events[3].Operation}
+	if !slices.Equal(ops, []Operation{OPSELECT, OPUPDATE, OPEXEC, OPDELETE}) {
+		t.Errorf("operations not match %v", ops)
+	}
+
+	if event := hook.events[0]; event.Rows != 2 || event.DbId != "main" || event.Query != "SELECT Id, Name FROM users" {
+		t.Errorf("select event not match %+v", event)
+	}
+
+	if event := hook.events[1]; event.Rows != 2 || !slices.Equal(event.Args, []any{1, "bob"}) {
+		t.Errorf("update event not match %+v", event)
+	}
+
+	if len(beginner.tx.queries) != 1 || !beginner.tx.committed {
+		t.Errorf("transaction query must go through the transaction handler")
+	}
+
+	if !slices.Equal(order, []string{"first", "second", "first", "second", "first", "second"}) {
+		t.Errorf("middleware order not match %v", order)
+	}
+}
+
+// обработчик, реализующий ResultHandler
+type resultFakeHandler struct {
+	fakeHandler
+}
+
+type lastIdResult int64
+
+func (r lastIdResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r lastIdResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (f *resultFakeHandler) ExecResultContext(_ context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	f.queries = append(f.queries, query)
+	return lastIdResult(42), nil
+}
+
+// middleware на основе HandlerWrapper, перехватывающий запросы строк
+type countingWrapper struct {
+	HandlerWrapper
+	calls *int
+}
+
+func (w *countingWrapper) QueryContext(ctx context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	*w.calls++
+	return w.HandlerWrapper.QueryContext(ctx, query, queryConfig, args...)
+}
+
+func TestMiddlewareForwarding(t *testing.T) {
+	db := GetDb(nil, "main")
+	handler := &resultFakeHandler{}
+	handler.columns = []string{"Id", "Name"}
+	handler.rows = [][]any{{1, "bob"}}
+	db.ChangeHandler(handler)
+
+	var order []string
+	// middleware без RowsHandler и ResultHandler
+	db.Use(func(next DbHandler) DbHandler { return &orderHandler{DbHandler: next, name: "plain", order: &order} })
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db", Dialect: sqlstrings.POSTGRES}
+
+	count := 0
+	for _, err := range db.Iterate(qc.ChangeItem(txUser{})) {
+		if err != nil {
+			t.Fatalf("Iterate through a plain middleware failed: %s", err)
+		}
+		count++
+	}
+	if count != 1 {
+		t.Errorf("wrong number of rows %d", count)
+	}
+
+	handler.rows = [][]any{{7}}
+	var id int
+	if err := db.InsertScan(qc.ChangeItem(txUser{Name: "bob"}).ChangeReturning("Id"), &id); err != nil || id != 7 {
+		t.Errorf("InsertScan through a plain middleware failed: %d %v", id, err)
+	}
+
+	res, err := db.ExecResult("INSERT INTO users (Name) VALUES ($1)", "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lastId, err := res.LastInsertId(); err != nil || lastId != 42 {
+		t.Errorf("result of the handler must be forwarded: %d %v", lastId, err)
+	}
+
+	calls := 0
+	db.Use(func(next DbHandler) DbHandler {
+		return &countingWrapper{HandlerWrapper: HandlerWrapper{next}, calls: &calls}
+	})
+
+	handler.rows = [][]any{{8}}
+	if err := db.InsertScan(qc.ChangeItem(txUser{Name: "eve"}).ChangeReturning("Id"), &id); err != nil || id != 8 || calls != 1 {
+		t.Errorf("HandlerWrapper must intercept row queries: %d %d %v", id, calls, err)
+	}
+}
+
+func TestHookRows(t *testing.T) {
+	db := GetDb(nil, "main")
+	handler := &fakeHandler{columns: []string{"Id", "Name"}, rows: [][]any{{1, "bob"}, {2, "alice"}}}
+	db.ChangeHandler(handler)
+
+	hook := &recordingHook{}
+	db.AddHook(hook)
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db"}
+
+	// After вызывается после чтения всех строк, а не при открытии
+	count := 0
+	for _, err := range db.Iterate(qc.ChangeItem(txUser{})) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(hook.events) != 0 {
+			t.Fatalf("After must not be called before the rows are closed")
+		}
+		count++
+	}
+
+	if len(hook.events) != 1 || hook.events[0].Rows != 2 || hook.errs[0] != nil || count != 2 {
+		t.Fatalf("iteration not reported: %v %v", hook.events, hook.errs)
+	}
+
+	// ошибка чтения строк передается хуку
+	broken := errors.New("connection reset")
+	handler.rowsErr = broken
+
+	var iterErr error
+	for _, err := range db.Iterate(qc.ChangeItem(txUser{})) {
+		if err != nil {
+			iterErr = err
+		}
+	}
+
+	if !errors.Is(iterErr, broken) {
+		t.Errorf("iteration must fail, got %v", iterErr)
+	}
+
+	if len(hook.events) != 2 || hook.events[1].Rows != 2 || !errors.Is(hook.errs[1], broken) {
+		t.Errorf("hook must see the iteration error: %v %v", hook.events, hook.errs)
+	}
+}
@@ -0,0 +1,247 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Результат запроса, который читается построчно, *sql.Rows реализует этот интерфейс
+// ======================================================================================
+// Query result that is read row by row, *sql.Rows implements this interface
+type ResultRows interface {
+	sqlreflect.ColumnsRowScanner
+	Close() error
+	Err() error
+}
+
+// Обработчик, который умеет возвращать строки без их материализации в срез, StdDbHandler реализует этот интерфейс
+// ======================================================================================
+// Handler that is able to return rows without materializing them into a slice, StdDbHandler implements this interface
+type RowsHandler interface {
+	QueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (ResultRows, error)
+}
+
+// маппер для итерации, если у DB маппер отключен
+var defaultMapper = sqlreflect.GetMapper()
+
+//region Handlers Realization
+
+func (stdh *StdDbHandler) QueryContext(context context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	stdh.dbMutex.RLock()
+	defer stdh.dbMutex.RUnlock()
+
+	return stdh.db.QueryContext(context, query, args...)
+}
+
+func (stdt *StdTxHandler) QueryContext(context context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (ResultRows, error) {
+	return stdt.tx.QueryContext(context, query, args...)
+}
+
+//endregion
+
+//region DB Iteration
+
+// Возвращает итератор по записям, каждая запись имеет тип queryConfig.Item, строки закрываются при выходе из цикла
+// ======================================================================================
+// Returns an iterator over records, every record has the type of queryConfig.Item, rows are closed when the loop exits
+func (db *DB) Iterate(queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error] {
+	return db.IterateContext(context.Background(), queryConfig, args...)
+}
+
+func (db *DB) IterateContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error] {
+	db.handlerMutex.RLock()
+	handler := db.handler
+	db.handlerMutex.RUnlock()
+
+	return db.iterateWith(context, handler, "", queryConfig, args...)
+}
+
+// То же что и IterateContext, но строка запроса передается явно
+// ======================================================================================
+// Same as IterateContext, but the query string is passed explicitly
+func (db *DB) IterateQueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error] {
+	db.handlerMutex.RLock()
+	handler := db.handler
+	db.handlerMutex.RUnlock()
+
+	return db.iterateWith(context, handler, query, queryConfig, args...)
+}
+
+func (tx *Tx) Iterate(queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error] {
+	return tx.IterateContext(context.Background(), queryConfig, args...)
+}
+
+func (tx *Tx) IterateContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error] {
+	return tx.db.iterateWith(context, tx.handler, "", queryConfig, args...)
+}
+
+func (tx *Tx) IterateQueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error] {
+	return tx.db.iterateWith(context, tx.handler, query, queryConfig, args...)
+}
+
+// Если query пустая, то она генерируется из queryConfig
+// ======================================================================================
+// If query is empty, it is generated from queryConfig
+func (db *DB) iterateWith(context context.Context, handler DbHandler, query string, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[any, error] {
+	return func(yield func(any, error) bool) {
+		// итератор может быть запущен несколько раз, поэтому захваченные переменные не изменяются
+		queryConfig := db.prepareConfig(queryConfig)
+		query := query
+		args := args
+
+		if queryConfig.Item == nil {
+			yield(nil, ErrNilItem)
+			return
+		}
+
+		rowsHandler, ok := handler.(RowsHandler)
+		if !ok {
+			yield(nil, errors.New("handler does not support row iteration"))
+			return
+		}
+
+		if len(query) == 0 {
+			var err error
+			if query, err = db.getSelectQuery(queryConfig); err != nil {
+				yield(nil, err)
+				return
+			}
+			args = append(args, sqlstrings.WhereArgs(queryConfig)...)
+		}
+
+		rows, err := rowsHandler.QueryContext(context, query, queryConfig, args...)
+		if err != nil {
+			yield(nil, wrapQueryError(err, query, queryConfig))
+			return
+		}
+		defer rows.Close()
+
+		ogType := reflect.TypeOf(queryConfig.Item)
+		nonRefType := sqlreflect.ConversionTypeToNonRefType(ogType)
+
+		// столбцы сопоставляются с полями один раз на весь результат
+		scanRow, err := db.rowScanner(handler, nonRefType, rows, queryConfig)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for rows.Next() {
+			item := reflect.New(nonRefType)
+			if err := scanRow(item.Interface()); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			ogVal, err := sqlreflect.ConversionToOgType(item.Elem().Interface(), ogType)
+			if !yield(ogVal, err) || err != nil {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(nil, wrapQueryError(err, query, queryConfig))
+		}
+	}
+}
+
+// Возвращает функцию сканирования строки сканером обработчика, если он не умеет сканировать построчно, то используется StdScanner с маппером DB
+// ======================================================================================
+// Returns the row scan function of the handler scanner, if it is unable to scan row by row StdScanner with the DB mapper is used
+func (db *DB) rowScanner(handler DbHandler, itemType reflect.Type, rows ResultRows, queryConfig sqlstrings.QueryConfig) (func(dest any) error, error) {
+	switch scanner := handlerScanner(handler).(type) {
+	case sqlreflect.RowsPreparer:
+		return scanner.PrepareRows(itemType, rows, queryConfig)
+	case sqlreflect.RowByRowScanner:
+		return func(dest any) error {
+			return scanner.ScanRow(dest, rows, queryConfig)
+		}, nil
+	}
+
+	scanner := &sqlreflect.StdScanner{Mapper: db.typeMapper()}
+	return scanner.PrepareRows(itemType, rows, queryConfig)
+}
+
+// Обработчик, который сообщает свой сканер, StdDbHandler и StdTxHandler реализуют этот интерфейс
+// ======================================================================================
+// Handler that reports its scanner, StdDbHandler and StdTxHandler implement this interface
+type ScannerHandler interface {
+	Scanner() sqlreflect.Scanner
+}
+
+// Возвращает сканер обработчика, обертки (middleware, хуки, повторы) разворачиваются через метод Unwrap() DbHandler, nil если сканер неизвестен
+// ======================================================================================
+// Returns the scanner of the handler, wrappers (middlewares, hooks, retries) are unwrapped via the Unwrap() DbHandler method, nil if the scanner is unknown
+func handlerScanner(handler DbHandler) sqlreflect.Scanner {
+	for handler != nil {
+		if scannerHandler, ok := handler.(ScannerHandler); ok {
+			return scannerHandler.Scanner()
+		}
+
+		unwrapper, ok := handler.(interface{ Unwrap() DbHandler })
+		if !ok {
+			return nil
+		}
+		handler = unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// Возвращает маппер DB, если он отключен то defaultMapper
+// ======================================================================================
+// Returns the mapper of DB, if it is disabled defaultMapper is returned
+func (db *DB) typeMapper() *sqlreflect.Mapper {
+	db.mapperMutex.RLock()
+	mapper := db.mapper
+	db.mapperMutex.RUnlock()
+
+	if mapper == nil {
+		mapper = defaultMapper
+	}
+
+	return mapper
+}
+
+//endregion
+
+//region Generic Iteration
+
+// Возвращает итератор по записям типа T, если queryConfig.Item не указан то используется нулевое значение T
+// ======================================================================================
+// Returns an iterator over records of type T, if queryConfig.Item is not specified the zero value of T is used
+// Как и у SelectT, T должен быть структурой или указателем на нее / As with SelectT, T must be a struct or a pointer to it
+func Rows[T any](context context.Context, q Querier, queryConfig sqlstrings.QueryConfig, args ...any) iter.Seq2[T, error] {
+	if queryConfig.Item == nil {
+		queryConfig.Item = reflect.Zero(reflect.TypeFor[T]()).Interface()
+	}
+	queryConfig, configErr := typedConfig[T](queryConfig)
+
+	return func(yield func(T, error) bool) {
+		if configErr != nil {
+			var item T
+			yield(item, configErr)
+			return
+		}
+
+		for val, err := range q.IterateContext(context, queryConfig, args...) {
+			var item T
+			if err == nil {
+				var ok bool
+				item, ok = val.(T)
+				if !ok {
+					err = errors.New("type of queryConfig.Item does not match T")
+				}
+			}
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+//endregion
@@ -0,0 +1,98 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+func TestIterate(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rows: [][]any{{1, "first"}, {2, "second"}, {3, "third"}}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db", TableName: "users", Item: &Users{}}
+
+	var names []string
+	for val, err := range db.Iterate(qc) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		names = append(names, val.(*Users).Name)
+	}
+
+	if len(names) != 3 || names[2] != "third" {
+		t.Errorf("Iterate failed: %v", names)
+	}
+
+	if !handler.lastRows.closed {
+		t.Errorf("rows must be closed")
+	}
+
+	count := 0
+	for user, err := range Rows[Users](context.Background(), db, qc.ChangeItem(nil)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if user.Id != 1 {
+			t.Errorf("Rows failed: %#v", user)
+		}
+		count++
+		break
+	}
+
+	if count != 1 || !handler.lastRows.closed {
+		t.Errorf("rows must be closed on early break")
+	}
+
+	for _, err := range db.Iterate(qc.ChangeItem(nil)) {
+		if err == nil {
+			t.Errorf("nil Item must produce an error")
+		}
+	}
+}
+
+type scannerHandler struct {
+	fakeHandler
+	scanner sqlreflect.Scanner
+}
+
+func (s *scannerHandler) Scanner() sqlreflect.Scanner {
+	return s.scanner
+}
+
+func TestIterateScanner(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &scannerHandler{
+		fakeHandler: fakeHandler{rows: [][]any{{1, "first"}, {2, "second"}}, columns: []string{"Id", "Name"}},
+		scanner:     &sqlreflect.StdScanner{Mapper: sqlreflect.GetMapper(), UnknownColumns: sqlreflect.ERRORUNKNOWN},
+	}
+	db.ChangeHandler(handler)
+	db.AddHook(&recordingHook{})
+
+	qc := sqlstrings.QueryConfig{TagName: "db", TableName: "users", Item: Users{}}
+
+	count := 0
+	for _, err := range db.Iterate(qc) {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		count++
+	}
+
+	// столбцы запрашиваются один раз на весь результат
+	if count != 2 || handler.lastRows.columnsCalls != 1 {
+		t.Errorf("columns must be resolved once: rows %d, calls %d", count, handler.lastRows.columnsCalls)
+	}
+
+	// политика неизвестных столбцов берется из сканера обработчика
+	handler.columns = []string{"Id", "Nick"}
+	for _, err := range db.Iterate(qc) {
+		if !errors.Is(err, sqlreflect.ErrUnknownColumn) {
+			t.Errorf("expected ErrUnknownColumn, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gosql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type Roles struct {
+	Id    int    `db:"Id"`
+	Title string `db:"Title"`
+}
+
+type UserWithRole struct {
+	Users `join:"Users"`
+	Role  Roles `join:"Roles"`
+}
+
+func TestJoinScan(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{
+		columns: []string{"Roles__Title", "Users__Id", "Users__Name", "Roles__Id"},
+		rows:    [][]any{{"admin", 1, "first", 10}},
+	}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db"}
+	query := qc.StartJoinInto(UserWithRole{}, "Users").Join("RoleId", sqlstrings.TCC("Roles", "Id")).Result()
+
+	var res []UserWithRole
+	if err := db.SelectQuery(query, qc, &res); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(res) != 1 || res[0].Id != 1 || res[0].Name != "first" || res[0].Role.Id != 10 || res[0].Role.Title != "admin" {
+		t.Errorf("join scan failed: %#v", res)
+	}
+
+	if !strings.Contains(query, `Users.Id AS "Users__Id"`) {
+		t.Errorf("alias must be quoted: %s", query)
+	}
+
+	// POSTGRES возвращает псевдонимы без кавычек в нижнем регистре
+	handler.columns = []string{"roles__title", "users__id", "users__name", "roles__id"}
+	if err := db.SelectQuery(query, qc, &res); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(res) != 1 || res[0].Id != 1 || res[0].Name != "first" || res[0].Role.Id != 10 || res[0].Role.Title != "admin" {
+		t.Errorf("join scan of lower-cased aliases failed: %#v", res)
+	}
+}
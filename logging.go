@@ -0,0 +1,238 @@
+package gosql
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Значение, которым в логах заменяются аргументы полей с опцией тега secret / Value that replaces the arguments of fields with the secret tag option in logs
+const RedactedValue = "[REDACTED]"
+
+// Хук, который пишет каждый запрос в slog.Logger: операцию, таблицу, строку запроса, длительность, количество строк, DB.Id и ошибку
+// Level - уровень обычных запросов (по умолчанию Debug) ;
+// SlowThreshold - запросы дольше этого порога пишутся с уровнем SlowLevel (по умолчанию Warn), 0 - не используется ;
+// ErrorLevel - уровень запросов с ошибкой (по умолчанию Error) ;
+// LogArgs - писать аргументы запроса (по умолчанию выключено), аргументы столбцов с опцией тега secret заменяются на RedactedValue, см. RedactArgs ;
+// Настраивать поля нужно до выполнения запросов
+// ======================================================================================
+// Hook that writes every query into slog.Logger: operation, table, query string, duration, number of rows, DB.Id and error
+// Level - level of regular queries (Debug by default) ;
+// SlowThreshold - queries longer than this threshold are written with SlowLevel (Warn by default), 0 - not used ;
+// ErrorLevel - level of failed queries (Error by default) ;
+// LogArgs - write the query arguments (disabled by default), arguments of the columns with the secret tag option are replaced with RedactedValue, see RedactArgs ;
+// The fields must be configured before queries are executed
+type LogHook struct {
+	Level         slog.Level
+	SlowThreshold time.Duration
+	SlowLevel     slog.Level
+	ErrorLevel    slog.Level
+	LogArgs       bool
+	logger        atomic.Pointer[slog.Logger]
+}
+
+func GetLogHook(logger *slog.Logger) *LogHook {
+	hook := &LogHook{
+		Level:      slog.LevelDebug,
+		SlowLevel:  slog.LevelWarn,
+		ErrorLevel: slog.LevelError,
+	}
+	hook.SetLogger(logger)
+	return hook
+}
+
+// Заменяет логгер, nil отключает запись / Replaces the logger, nil disables writing
+func (h *LogHook) SetLogger(logger *slog.Logger) {
+	h.logger.Store(logger)
+}
+
+func (h *LogHook) Before(context context.Context, _ *QueryEvent) context.Context {
+	return context
+}
+
+func (h *LogHook) After(context context.Context, event *QueryEvent, err error, duration time.Duration) {
+	logger := h.logger.Load()
+	if logger == nil {
+		return
+	}
+
+	level, msg := h.Level, "query"
+	switch {
+	case err != nil:
+		level, msg = h.ErrorLevel, "query failed"
+	case h.SlowThreshold > 0 && duration >= h.SlowThreshold:
+		level, msg = h.SlowLevel, "slow query"
+	}
+
+	if !logger.Enabled(context, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("db_id", event.DbId),
+		slog.String("operation", event.Operation.String()),
+		slog.String("table", tableName(event.Config)),
+		slog.String("query", event.Query),
+		slog.Duration("duration", duration),
+		slog.Int("rows", event.Rows),
+	}
+
+	if h.LogArgs {
+		attrs = append(attrs, slog.Any("args", RedactArgs(event.Operation, event.Config, event.Args)))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	logger.LogAttrs(context, level, msg, attrs...)
+}
+
+// Добавляет запись запросов в logger, повторный вызов заменяет логгер, возвращает хук для настройки уровней
+// ======================================================================================
+// Adds logging of queries into logger, a repeated call replaces the logger, returns the hook for configuring the levels
+func (db *DB) SetLogger(logger *slog.Logger) *LogHook {
+	db.logMutex.Lock()
+	defer db.logMutex.Unlock()
+
+	if db.logHook != nil {
+		db.logHook.SetLogger(logger)
+		return db.logHook
+	}
+
+	db.logHook = GetLogHook(logger)
+	db.AddHook(db.logHook)
+
+	return db.logHook
+}
+
+// Возвращает копию args, в которой аргументы столбцов с опцией тега secret типа queryConfig.Item заменены на RedactedValue
+// Столбец аргумента определяется по его позиции: поля Item для Insert, Update и Upsert, ColumnName и условия Where
+// Аргументы, столбец которых определить нельзя (Exec, SelectQuery и аргументы, не совпадающие с запросом), тоже заменяются
+// ======================================================================================
+// Returns a copy of args where the arguments of the columns with the secret tag option of the queryConfig.Item type are replaced with RedactedValue
+// The column of an argument is determined by its position: the Item fields for Insert, Update and Upsert, ColumnName and the Where conditions
+// Arguments whose column cannot be determined (Exec, SelectQuery and arguments that do not match the query) are replaced as well
+func RedactArgs(operation Operation, queryConfig sqlstrings.QueryConfig, args []any) []any {
+	columns := argColumns(operation, queryConfig, len(args))
+	secrets := secretColumns(queryConfig)
+
+	res := make([]any, len(args))
+	for idx, arg := range args {
+		res[idx] = arg
+
+		// имя столбца Where может содержать имя таблицы через точку
+		column := columns[idx]
+		if dot := strings.LastIndex(column, "."); dot >= 0 {
+			column = column[dot+1:]
+		}
+
+		if len(column) == 0 || secrets[strings.ToLower(column)] {
+			res[idx] = RedactedValue
+		}
+	}
+
+	return res
+}
+
+// Возвращает столбцы count аргументов запроса по позициям, для неизвестных позиций - пустую строку
+func argColumns(operation Operation, queryConfig sqlstrings.QueryConfig, count int) []string {
+	columns := make([]string, count)
+
+	// аргументы Where всегда передаются последними
+	where := sqlstrings.WhereArgColumns(queryConfig)
+	if len(where) > count {
+		return columns
+	}
+	lead := count - len(where)
+	copy(columns[lead:], where)
+
+	var itemColumns []string
+	switch operation {
+	case OPINSERT, OPUPDATE, OPUPSERT:
+		itemColumns = itemArgColumns(operation, queryConfig, lead)
+	case OPSELECT, OPDELETE:
+		if len(queryConfig.ColumnName) > 0 {
+			itemColumns = []string{queryConfig.ColumnName}
+		}
+	}
+
+	if len(itemColumns) == lead {
+		copy(columns, itemColumns)
+	}
+
+	return columns
+}
+
+// Возвращает столбцы аргументов, которые берутся из полей Item, с учетом пачек InsertMany
+func itemArgColumns(operation Operation, queryConfig sqlstrings.QueryConfig, count int) []string {
+	if queryConfig.Item == nil {
+		return nil
+	}
+
+	typeMap, err := defaultMapper.Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
+	if err != nil {
+		return nil
+	}
+
+	switch operation {
+	case OPINSERT:
+		queryConfig.QueryType = sqlstrings.INSERT
+	case OPUPDATE:
+		queryConfig.QueryType = sqlstrings.UPDATE
+	case OPUPSERT:
+		queryConfig.QueryType = sqlstrings.UPSERT
+	}
+
+	columns := sqlreflect.GetFieldsColumnsOfItem(queryConfig, typeMap, true)
+	if len(columns) == count || operation != OPINSERT {
+		return columns
+	}
+
+	// InsertMany передает значения нескольких записей без учета omitempty
+	perRow := sqlreflect.GetFieldsColumnsOfItem(queryConfig, typeMap, false)
+	if len(perRow) == 0 || count%len(perRow) != 0 {
+		return nil
+	}
+
+	columns = make([]string, 0, count)
+	for len(columns) < count {
+		columns = append(columns, perRow...)
+	}
+	return columns
+}
+
+// Возвращает столбцы полей с опцией тега secret в нижнем регистре
+func secretColumns(queryConfig sqlstrings.QueryConfig) map[string]bool {
+	if queryConfig.Item == nil {
+		return nil
+	}
+
+	typeMap, err := defaultMapper.Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
+	if err != nil {
+		return nil
+	}
+
+	secrets := map[string]bool{}
+	for _, fieldInfo := range typeMap.Fields {
+		if fieldInfo.Secret {
+			secrets[strings.ToLower(fieldInfo.FTag)] = true
+		}
+	}
+
+	return secrets
+}
+
+// Имя таблицы запроса: TableName или имя типа Item / Table name of the query: TableName or the name of the Item type
+func tableName(queryConfig sqlstrings.QueryConfig) string {
+	if len(queryConfig.TableName) > 0 || queryConfig.Item == nil {
+		return queryConfig.TableName
+	}
+	return sqlreflect.ConversionValToNonRefType(queryConfig.Item).Name()
+}
@@ -0,0 +1,107 @@
+package gosql
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type secretUser struct {
+	Id       int    `db:"Id,pk,auto"`
+	Login    string `db:"Login"`
+	Password string `db:"Password,secret"`
+}
+
+func TestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	db := GetDb(nil, "main")
+	handler := &fakeHandler{}
+	db.ChangeHandler(handler)
+
+	hook := db.SetLogger(logger)
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db"}
+
+	// по умолчанию аргументы не пишутся
+	if _, err := db.Insert(qc.ChangeItem(secretUser{Login: "bob", Password: "qwerty"})); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out := buf.String(); strings.Contains(out, "args=") {
+		t.Errorf("args must not be logged by default: %s", out)
+	}
+
+	buf.Reset()
+	hook.LogArgs = true
+	if _, err := db.Insert(qc.ChangeItem(secretUser{Login: "bob", Password: "qwerty"})); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out := buf.String()
+	for _, part := range []string{"level=DEBUG", "db_id=main", "operation=insert", "table=users", "rows=1", "[bob [REDACTED]]"} {
+		if !strings.Contains(out, part) {
+			t.Errorf("log must contain %q: %s", part, out)
+		}
+	}
+
+	if strings.Contains(out, "qwerty") {
+		t.Errorf("secret value must be redacted: %s", out)
+	}
+
+	// аргументы Where по secret столбцу скрываются, даже если Item не содержит значения
+	buf.Reset()
+	where := qc.ChangeItem(secretUser{}).ChangeWhere(sqlstrings.And(sqlstrings.Eq("Login", "bob"), sqlstrings.Eq("users.password", "hash")))
+	var users []secretUser
+	if err := db.Select(where, &users); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out = buf.String(); strings.Contains(out, "hash") || !strings.Contains(out, "[bob [REDACTED]]") {
+		t.Errorf("where argument of the secret column must be redacted: %s", out)
+	}
+
+	// у сырых запросов столбцы аргументов неизвестны
+	buf.Reset()
+	db.Exec("UPDATE users SET Password = $1 WHERE Login = $2", "qwerty", "bob")
+	if out = buf.String(); strings.Contains(out, "qwerty") || !strings.Contains(out, "[[REDACTED] [REDACTED]]") {
+		t.Errorf("raw query arguments must be redacted: %s", out)
+	}
+
+	// InsertMany передает значения нескольких записей
+	buf.Reset()
+	if _, err := db.InsertMany(qc, []secretUser{{Login: "a", Password: "p1"}, {Login: "b"}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out = buf.String(); strings.Contains(out, "p1") || !strings.Contains(out, "[a [REDACTED] b [REDACTED]]") {
+		t.Errorf("batch arguments must be redacted: %s", out)
+	}
+
+	// повторный вызов заменяет логгер, а не добавляет второй хук
+	buf.Reset()
+	if db.SetLogger(logger) != hook {
+		t.Errorf("the same hook must be returned")
+	}
+
+	hook.Level = slog.LevelInfo
+	handler.err = errors.New("boom")
+	db.Exec("DELETE FROM users")
+
+	out = buf.String()
+	if strings.Count(out, "\n") != 1 || !strings.Contains(out, "level=ERROR") || !strings.Contains(out, `error=boom`) {
+		t.Errorf("error not logged: %s", out)
+	}
+
+	buf.Reset()
+	handler.err = nil
+	hook.SlowThreshold = time.Nanosecond
+	db.Exec("DELETE FROM users")
+
+	if out = buf.String(); !strings.Contains(out, "level=WARN") || !strings.Contains(out, `msg="slow query"`) {
+		t.Errorf("slow query not logged: %s", out)
+	}
+}
@@ -0,0 +1,217 @@
+package gosql
+
+import (
+	"context"
+	"expvar"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// DbId - DB.Id, метка экземпляра ;
+// Operation - вид операции ;
+// Table - таблица запроса, пустая для запросов без конфигурации ;
+// Fingerprint - отпечаток запроса (sqlstrings.Fingerprint), запросы отличающиеся только значениями имеют один отпечаток ;
+// ======================================================================================
+// DbId - DB.Id, the instance label ;
+// Operation - kind of the operation ;
+// Table - table of the query, empty for queries without configuration ;
+// Fingerprint - fingerprint of the query (sqlstrings.Fingerprint), queries that differ only in values have the same fingerprint ;
+type MetricLabels struct {
+	DbId        string
+	Operation   string
+	Table       string
+	Fingerprint string
+}
+
+// Сборщик метрик запросов, вызывается после каждого обращения к обработчику
+// ======================================================================================
+// Collector of query metrics, it is called after every call to the handler
+type Metrics interface {
+	ObserveQuery(labels MetricLabels, duration time.Duration, err error)
+}
+
+// Включает сбор метрик запросов, повторный вызов заменяет сборщик, nil отключает сбор
+// ======================================================================================
+// Enables collection of query metrics, a repeated call replaces the collector, nil disables the collection
+func (db *DB) SetMetrics(metrics Metrics) {
+	db.metricsOnce.Do(func() {
+		db.AddHook(&db.metricsHook)
+	})
+	db.metricsHook.metrics.Store(&metricsHolder{metrics: metrics})
+}
+
+type metricsHolder struct {
+	metrics Metrics
+}
+
+type metricsHook struct {
+	metrics atomic.Pointer[metricsHolder]
+}
+
+func (h *metricsHook) Before(context context.Context, _ *QueryEvent) context.Context {
+	return context
+}
+
+func (h *metricsHook) After(_ context.Context, event *QueryEvent, err error, duration time.Duration) {
+	holder := h.metrics.Load()
+	if holder == nil || holder.metrics == nil {
+		return
+	}
+
+	holder.metrics.ObserveQuery(MetricLabels{
+		DbId:        event.DbId,
+		Operation:   event.Operation.String(),
+		Table:       tableName(event.Config),
+		Fingerprint: fingerprint(event.Query),
+	}, duration, err)
+}
+
+// Максимальное количество запомненных отпечатков / Maximum number of remembered fingerprints
+const maxFingerprints = 4096
+
+var (
+	fingerprints     sync.Map
+	fingerprintCount atomic.Int64
+)
+
+// Отпечатки сгенерированных запросов запоминаются, запросы с литералами в тексте могут быть уникальными, поэтому кэш ограничен
+func fingerprint(query string) string {
+	if res, ok := fingerprints.Load(query); ok {
+		return res.(string)
+	}
+
+	res := sqlstrings.Fingerprint(query)
+	if fingerprintCount.Load() < maxFingerprints {
+		if _, loaded := fingerprints.LoadOrStore(query, res); !loaded {
+			fingerprintCount.Add(1)
+		}
+	}
+
+	return res
+}
+
+//region Expvar metrics
+
+// Верхние границы корзин гистограммы задержек по умолчанию / Default upper bounds of the latency histogram buckets
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// Сборщик метрик, публикующий их через expvar (/debug/vars)
+// Ключ первого уровня - "db_id:operation:table", значение содержит count, errors, duration_ns и гистограмму latency с корзинами le_<граница> и le_inf
+// Ключ "fingerprints" содержит количество запросов по отпечаткам
+// QPS вычисляется по приращению count между опросами
+// ======================================================================================
+// Metrics collector that publishes them via expvar (/debug/vars)
+// The first level key is "db_id:operation:table", the value contains count, errors, duration_ns and the latency histogram with le_<bound> and le_inf buckets
+// The "fingerprints" key contains the number of queries by fingerprints
+// QPS is calculated from the increment of count between polls
+type ExpvarMetrics struct {
+	Buckets      []time.Duration
+	vars         *expvar.Map
+	fingerprints *expvar.Map
+	mutex        sync.Mutex
+}
+
+// Возвращает сборщик, опубликованный под именем name, повторный вызов с тем же именем возвращает новый сборщик над той же переменной
+// ======================================================================================
+// Returns the collector published under the name, a repeated call with the same name returns a new collector over the same variable
+func GetExpvarMetrics(name string) *ExpvarMetrics {
+	vars, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		vars = expvar.NewMap(name)
+	}
+
+	fingerprints, ok := vars.Get("fingerprints").(*expvar.Map)
+	if !ok {
+		fingerprints = new(expvar.Map)
+		vars.Set("fingerprints", fingerprints)
+	}
+
+	return &ExpvarMetrics{
+		Buckets:      DefaultLatencyBuckets,
+		vars:         vars,
+		fingerprints: fingerprints,
+	}
+}
+
+func (m *ExpvarMetrics) ObserveQuery(labels MetricLabels, duration time.Duration, err error) {
+	stats := m.stats(labels.DbId + ":" + labels.Operation + ":" + labels.Table)
+
+	stats.Add("count", 1)
+	stats.Add("duration_ns", duration.Nanoseconds())
+	if err != nil {
+		stats.Add("errors", 1)
+	}
+
+	latency := stats.Get("latency").(*expvar.Map)
+	bucket := "le_inf"
+	for _, bound := range m.Buckets {
+		if duration <= bound {
+			bucket = "le_" + bound.String()
+			break
+		}
+	}
+	latency.Add(bucket, 1)
+
+	if len(labels.Fingerprint) > 0 {
+		m.fingerprints.Add(labels.Fingerprint, 1)
+	}
+}
+
+func (m *ExpvarMetrics) stats(key string) *expvar.Map {
+	if stats, ok := m.vars.Get(key).(*expvar.Map); ok {
+		return stats
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if stats, ok := m.vars.Get(key).(*expvar.Map); ok {
+		return stats
+	}
+
+	// корзины создаются заранее, чтобы гистограмма была видна целиком
+	latency := new(expvar.Map)
+	for _, bound := range m.Buckets {
+		latency.Add("le_"+bound.String(), 0)
+	}
+	latency.Add("le_inf", 0)
+
+	stats := new(expvar.Map)
+	stats.Add("count", 0)
+	stats.Add("errors", 0)
+	stats.Add("duration_ns", 0)
+	stats.Set("latency", latency)
+
+	m.vars.Set(key, stats)
+
+	return stats
+}
+
+// Возвращает значение счетчика count, errors или duration_ns по ключу "db_id:operation:table" / Returns the value of the count, errors or duration_ns counter by the "db_id:operation:table" key
+func (m *ExpvarMetrics) Value(key string, counter string) int64 {
+	stats, ok := m.vars.Get(key).(*expvar.Map)
+	if !ok {
+		return 0
+	}
+	variable := stats.Get(counter)
+	if variable == nil {
+		return 0
+	}
+	value, _ := strconv.ParseInt(variable.String(), 10, 64)
+	return value
+}
+
+//endregion
@@ -0,0 +1,90 @@
+package gosql
+
+import (
+	"errors"
+	"expvar"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type recordingMetrics struct {
+	labels []MetricLabels
+	errs   []error
+}
+
+func (m *recordingMetrics) ObserveQuery(labels MetricLabels, _ time.Duration, err error) {
+	m.labels = append(m.labels, labels)
+	m.errs = append(m.errs, err)
+}
+
+func TestMetrics(t *testing.T) {
+	db := GetDb(nil, "main")
+	handler := &fakeHandler{}
+	db.ChangeHandler(handler)
+
+	metrics := &recordingMetrics{}
+	db.SetMetrics(metrics)
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db", Dialect: sqlstrings.POSTGRES}
+	if _, err := db.Insert(qc.ChangeItem(secretUser{Login: "bob", Password: "qwerty"})); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	handler.err = errors.New("boom")
+	db.Exec("DELETE FROM users WHERE Id IN (1, 2, 3)")
+
+	if len(metrics.labels) != 2 {
+		t.Fatalf("expected 2 observations, got %d", len(metrics.labels))
+	}
+
+	first := metrics.labels[0]
+	if first.DbId != "main" || first.Operation != "insert" || first.Table != "users" || strings.Contains(first.Fingerprint, "$1") {
+		t.Errorf("wrong labels: %+v", first)
+	}
+
+	if second := metrics.labels[1]; second.Operation != "exec" || second.Fingerprint != "DELETE FROM users WHERE Id IN (...)" || metrics.errs[1] == nil {
+		t.Errorf("wrong labels: %+v %v", second, metrics.errs[1])
+	}
+
+	// nil отключает сбор
+	db.SetMetrics(nil)
+	db.Exec("DELETE FROM users")
+	if len(metrics.labels) != 2 {
+		t.Errorf("metrics must be disabled")
+	}
+}
+
+func TestExpvarMetrics(t *testing.T) {
+	// переменные expvar глобальны, поэтому каждый запуск теста использует свой DbId
+	metrics := GetExpvarMetrics("gosql_test")
+	dbId := "main" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	labels := MetricLabels{DbId: dbId, Operation: "select", Table: "users", Fingerprint: "SELECT * FROM users WHERE Id = ?"}
+
+	metrics.ObserveQuery(labels, 2*time.Millisecond, nil)
+	metrics.ObserveQuery(labels, 2*time.Second, errors.New("boom"))
+
+	key := dbId + ":select:users"
+	if metrics.Value(key, "count") != 2 || metrics.Value(key, "errors") != 1 {
+		t.Errorf("wrong counters: %s", expvar.Get("gosql_test"))
+	}
+
+	out := expvar.Get("gosql_test").(*expvar.Map).Get(key).String()
+	for _, part := range []string{`"le_5ms": 1`, `"le_5s": 1`, `"le_inf": 0`} {
+		if !strings.Contains(out, part) {
+			t.Errorf("expvar must contain %q: %s", part, out)
+		}
+	}
+
+	if !strings.Contains(expvar.Get("gosql_test").String(), `"SELECT * FROM users WHERE Id = ?": `) {
+		t.Errorf("fingerprint is not counted")
+	}
+
+	// повторная публикация под тем же именем не паникует
+	if GetExpvarMetrics("gosql_test").Value(key, "count") != 2 {
+		t.Errorf("the same variable must be reused")
+	}
+}
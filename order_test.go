@@ -0,0 +1,32 @@
+package gosql
+
+import (
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+func TestSelectOrderBy(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rows: [][]any{{1, "first"}}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db", Item: Users{}, Limit: 1}
+
+	var users []Users
+	if err := db.Select(qc.ChangeOrderBy(sqlstrings.Desc("Id")), &users); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if handler.queries[0] != "SELECT Id, Name FROM Users ORDER BY Id DESC LIMIT 1" {
+		t.Errorf("query failed: %s", handler.queries[0])
+	}
+
+	if err := db.Select(qc.ChangeOrderBy(sqlstrings.Asc("Id DESC; --")), &users); err == nil {
+		t.Errorf("unknown sort column must be rejected")
+	}
+
+	if len(handler.queries) != 1 {
+		t.Errorf("query with unknown sort column must not be executed")
+	}
+}
@@ -0,0 +1,259 @@
+package gosql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Size - количество записей на странице ;
+// After - курсор PageInfo.Next предыдущей страницы, возвращает записи после него ;
+// Before - курсор PageInfo.Prev, возвращает записи перед ним, указывается только одно из After и Before ;
+// ======================================================================================
+// Size - number of records on the page ;
+// After - the PageInfo.Next cursor of the previous page, records after it are returned ;
+// Before - the PageInfo.Prev cursor, records before it are returned, only one of After and Before can be specified ;
+type PageRequest struct {
+	Size   int
+	After  string
+	Before string
+}
+
+// Непрозрачные курсоры соседних страниц, пустая строка если страницы нет
+// ======================================================================================
+// Opaque cursors of the neighbouring pages, an empty string if there is no page
+type PageInfo struct {
+	Next string
+	Prev string
+}
+
+//region Keyset Pagination
+
+// Возвращает страницу записей в dest (указатель на срез), сортировка выполняется по queryConfig.OrderBy, который должен задавать уникальный ключ без NULL значений
+// Вместо OFFSET используется условие (a, b) > ($1, $2) по значениям ключа из курсора, поэтому страницы стабильны при добавлении записей
+// Курсор - base64 от значений полей ключа последней (первой) записи страницы
+// ======================================================================================
+// Returns a page of records into dest (a pointer to slice), sorting is done by queryConfig.OrderBy, which must define a unique key without NULL values
+// Instead of OFFSET the (a, b) > ($1, $2) condition on the key values from the cursor is used, so pages are stable when records are inserted
+// The cursor is base64 of the key field values of the last (first) record of the page
+func (db *DB) Page(queryConfig sqlstrings.QueryConfig, dest any, request PageRequest, args ...any) (PageInfo, error) {
+	return db.PageContext(context.Background(), queryConfig, dest, request, args...)
+}
+
+func (db *DB) PageContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, request PageRequest, args ...any) (PageInfo, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.pageWith(context, db.handler, queryConfig, dest, request, args...)
+}
+
+func (tx *Tx) Page(queryConfig sqlstrings.QueryConfig, dest any, request PageRequest, args ...any) (PageInfo, error) {
+	return tx.PageContext(context.Background(), queryConfig, dest, request, args...)
+}
+
+func (tx *Tx) PageContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, request PageRequest, args ...any) (PageInfo, error) {
+	return tx.db.pageWith(context, tx.handler, queryConfig, dest, request, args...)
+}
+
+func (db *DB) pageWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, dest any, request PageRequest, args ...any) (PageInfo, error) {
+	var info PageInfo
+
+	queryConfig = db.prepareConfig(queryConfig)
+
+	if len(queryConfig.OrderBy) == 0 {
+		return info, errors.New("queryConfig parameter OrderBy must be specified for pagination")
+	}
+
+	if request.Size <= 0 {
+		return info, errors.New("page size must be greater than 0")
+	}
+
+	if len(request.After) > 0 && len(request.Before) > 0 {
+		return info, errors.New("only one of After and Before can be specified")
+	}
+
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Pointer || destVal.Elem().Kind() != reflect.Slice {
+		return info, fmt.Errorf("%w: dest must be a pointer to slice", ErrInvalidDest)
+	}
+
+	sliceType := destVal.Elem().Type()
+
+	if queryConfig.Item == nil {
+		queryConfig.Item = reflect.Zero(sqlreflect.ConversionTypeToNonRefType(sliceType.Elem())).Interface()
+	}
+
+	if err := sqlstrings.ValidateOrderBy(queryConfig); err != nil {
+		return info, err
+	}
+
+	typeMap, err := db.typeMapper().Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
+	if err != nil {
+		return info, err
+	}
+
+	keyFields, err := pageKeyFields(typeMap, queryConfig.OrderBy)
+	if err != nil {
+		return info, err
+	}
+
+	backward := len(request.Before) > 0
+
+	cursor := request.After
+	orders := slices.Clone(queryConfig.OrderBy)
+
+	// предыдущая страница выбирается в обратном порядке сортировки и затем разворачивается
+	if backward {
+		cursor = request.Before
+		for idx := range orders {
+			orders[idx] = reverseOrder(orders[idx])
+		}
+	}
+
+	pageConfig := queryConfig
+	pageConfig.OrderBy = orders
+	pageConfig.Limit = request.Size + 1
+	pageConfig.Offset = 0
+
+	if len(cursor) > 0 {
+		values, err := decodeCursor(cursor, keyFields)
+		if err != nil {
+			return info, err
+		}
+		pageConfig.Where = sqlstrings.And(queryConfig.Where, sqlstrings.Seek(orders, values...))
+	}
+
+	rows := reflect.New(sliceType)
+	if err := db.selectWith(context, handler, pageConfig, rows.Interface(), args...); err != nil {
+		return info, err
+	}
+
+	slice := rows.Elem()
+
+	hasMore := slice.Len() > request.Size
+	if hasMore {
+		slice = slice.Slice(0, request.Size)
+	}
+
+	if backward {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if length := slice.Len(); length > 0 {
+		first, err := encodeCursor(slice.Index(0), keyFields)
+		if err != nil {
+			return info, err
+		}
+
+		last, err := encodeCursor(slice.Index(length-1), keyFields)
+		if err != nil {
+			return info, err
+		}
+
+		if backward {
+			info.Next = last
+			if hasMore {
+				info.Prev = first
+			}
+		} else {
+			if hasMore {
+				info.Next = last
+			}
+			if len(cursor) > 0 {
+				info.Prev = first
+			}
+		}
+	}
+
+	destVal.Elem().Set(slice)
+
+	return info, nil
+}
+
+func reverseOrder(order sqlstrings.Order) sqlstrings.Order {
+	order.Desc = !order.Desc
+	switch order.Nulls {
+	case sqlstrings.NULLSFIRST:
+		order.Nulls = sqlstrings.NULLSLAST
+	case sqlstrings.NULLSLAST:
+		order.Nulls = sqlstrings.NULLSFIRST
+	}
+	return order
+}
+
+// Возвращает поля структуры, соответствующие столбцам сортировки
+func pageKeyFields(typeMap *sqlreflect.TypeMap, orders []sqlstrings.Order) ([]*sqlreflect.FieldInfo, error) {
+	fields := make([]*sqlreflect.FieldInfo, 0, len(orders))
+
+	for _, order := range orders {
+		idx := slices.IndexFunc(typeMap.Fields, func(f *sqlreflect.FieldInfo) bool { return f.FTag == order.Column })
+		if idx < 0 {
+			return nil, errors.New("sort column " + order.Column + " is not mapped to a field")
+		}
+		fields = append(fields, typeMap.Fields[idx])
+	}
+
+	return fields, nil
+}
+
+func encodeCursor(item reflect.Value, fields []*sqlreflect.FieldInfo) (string, error) {
+	for item.Kind() == reflect.Pointer {
+		item = item.Elem()
+	}
+
+	values := make([]any, 0, len(fields))
+	for _, fieldInfo := range fields {
+		field := item.FieldByIndex(fieldInfo.Index)
+		if field.Kind() == reflect.Pointer && !field.IsNil() {
+			field = field.Elem()
+		}
+		values = append(values, field.Interface())
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Значения курсора приводятся к типам полей ключа, поэтому в запрос не может попасть ничего кроме аргументов
+// ======================================================================================
+// The cursor values are converted to the types of the key fields, so nothing but arguments can get into the query
+func decodeCursor(cursor string, fields []*sqlreflect.FieldInfo) ([]any, error) {
+	invalid := errors.New("invalid page cursor")
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) != len(fields) {
+		return nil, invalid
+	}
+
+	values := make([]any, 0, len(fields))
+	for idx, fieldInfo := range fields {
+		value := reflect.New(sqlreflect.ConversionTypeToNonRefType(fieldInfo.Ftype))
+		if err := json.Unmarshal(raw[idx], value.Interface()); err != nil {
+			return nil, invalid
+		}
+		values = append(values, value.Elem().Interface())
+	}
+
+	return values, nil
+}
+
+//endregion
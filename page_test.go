@@ -0,0 +1,68 @@
+package gosql
+
+import (
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+func TestPage(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rows: [][]any{{1, "first"}, {2, "second"}, {3, "third"}}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db", OrderBy: []sqlstrings.Order{sqlstrings.Asc("Id")}}
+
+	var users []Users
+	info, err := db.Page(qc, &users, PageRequest{Size: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(users) != 2 || users[1].Id != 2 || len(info.Next) == 0 || len(info.Prev) != 0 {
+		t.Errorf("first page failed: %#v %#v", users, info)
+	}
+
+	if handler.queries[0] != "SELECT Id, Name FROM Users ORDER BY Id ASC LIMIT 3" {
+		t.Errorf("first page query failed: %s", handler.queries[0])
+	}
+
+	handler.rows = [][]any{{3, "third"}}
+
+	info, err = db.Page(qc, &users, PageRequest{Size: 2, After: info.Next})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if handler.queries[1] != "SELECT Id, Name FROM Users WHERE Id > $1 ORDER BY Id ASC LIMIT 3" {
+		t.Errorf("next page query failed: %s", handler.queries[1])
+	}
+
+	if len(handler.args[1]) != 1 || handler.args[1][0] != 2 {
+		t.Errorf("cursor value failed: %#v", handler.args[1])
+	}
+
+	if len(users) != 1 || len(info.Next) != 0 || len(info.Prev) == 0 {
+		t.Errorf("last page failed: %#v %#v", users, info)
+	}
+
+	// строки возвращаются в обратном порядке и разворачиваются
+	handler.rows = [][]any{{2, "second"}, {1, "first"}}
+
+	info, err = db.Page(qc, &users, PageRequest{Size: 2, Before: info.Prev})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if handler.queries[2] != "SELECT Id, Name FROM Users WHERE Id < $1 ORDER BY Id DESC LIMIT 3" {
+		t.Errorf("prev page query failed: %s", handler.queries[2])
+	}
+
+	if len(users) != 2 || users[0].Id != 1 || len(info.Next) == 0 || len(info.Prev) != 0 {
+		t.Errorf("prev page failed: %#v %#v", users, info)
+	}
+
+	if _, err := db.Page(qc, &users, PageRequest{Size: 2, After: "garbage"}); err == nil {
+		t.Errorf("invalid cursor must be rejected")
+	}
+}
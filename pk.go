@@ -0,0 +1,178 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+//region Primary key
+
+// У модели нет полей с опцией тега pk / The model has no fields with the pk tag option
+var ErrNoPrimaryKey = errors.New("item has no primary key fields")
+
+// Обновляет запись item по первичному ключу (поля с опцией тега pk): UPDATE ... SET ... WHERE k1 = $x AND k2 = $y
+// Из queryConfig используются TableName, NameWrapper, TagName, ExcludedTags, Dialect и Where, которое объединяется с условием ключа через AND
+// ======================================================================================
+// Updates the item record by the primary key (fields with the pk tag option): UPDATE ... SET ... WHERE k1 = $x AND k2 = $y
+// TableName, NameWrapper, TagName, ExcludedTags, Dialect and Where, which is combined with the key condition by AND, are used from queryConfig
+func (db *DB) UpdateItem(queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	return db.UpdateItemContext(context.Background(), queryConfig, item)
+}
+
+func (db *DB) UpdateItemContext(context context.Context, queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.updateItemWith(context, db.handler, queryConfig, item)
+}
+
+// Удаляет запись item по первичному ключу: DELETE FROM ... WHERE k1 = $1 AND k2 = $2
+// ======================================================================================
+// Deletes the item record by the primary key: DELETE FROM ... WHERE k1 = $1 AND k2 = $2
+func (db *DB) DeleteItem(queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	return db.DeleteItemContext(context.Background(), queryConfig, item)
+}
+
+func (db *DB) DeleteItemContext(context context.Context, queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.deleteItemWith(context, db.handler, queryConfig, item)
+}
+
+// Получает одну запись по значениям первичного ключа keys, которые передаются в порядке объявления полей ключа, dest - указатель на структуру
+// ======================================================================================
+// Gets one record by the keys values of the primary key, they are passed in the declaration order of the key fields, dest is a pointer to the struct
+func (db *DB) GetByPK(queryConfig sqlstrings.QueryConfig, dest any, keys ...any) error {
+	return db.GetByPKContext(context.Background(), queryConfig, dest, keys...)
+}
+
+func (db *DB) GetByPKContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, keys ...any) error {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.getByPKWith(context, db.handler, queryConfig, dest, keys...)
+}
+
+func (tx *Tx) UpdateItem(queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	return tx.UpdateItemContext(context.Background(), queryConfig, item)
+}
+
+func (tx *Tx) UpdateItemContext(context context.Context, queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	return tx.db.updateItemWith(context, tx.handler, queryConfig, item)
+}
+
+func (tx *Tx) DeleteItem(queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	return tx.DeleteItemContext(context.Background(), queryConfig, item)
+}
+
+func (tx *Tx) DeleteItemContext(context context.Context, queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	return tx.db.deleteItemWith(context, tx.handler, queryConfig, item)
+}
+
+func (tx *Tx) GetByPK(queryConfig sqlstrings.QueryConfig, dest any, keys ...any) error {
+	return tx.GetByPKContext(context.Background(), queryConfig, dest, keys...)
+}
+
+func (tx *Tx) GetByPKContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, keys ...any) error {
+	return tx.db.getByPKWith(context, tx.handler, queryConfig, dest, keys...)
+}
+
+func (db *DB) updateItemWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	queryConfig, err := db.itemPKConfig(queryConfig, item)
+	if err != nil {
+		return -1, err
+	}
+
+	return db.updateWith(context, handler, queryConfig)
+}
+
+func (db *DB) deleteItemWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, item any) (int, error) {
+	queryConfig, err := db.itemPKConfig(queryConfig, item)
+	if err != nil {
+		return -1, err
+	}
+
+	return db.deleteWith(context, handler, queryConfig)
+}
+
+func (db *DB) getByPKWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, dest any, keys ...any) error {
+	tdest := reflect.TypeOf(dest)
+	if tdest == nil || tdest.Kind() != reflect.Pointer || tdest.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: dest must be a pointer to the struct", ErrInvalidDest)
+	}
+
+	typeMap, err := db.typeMapper().Map(tdest.Elem(), queryConfig.TagName)
+	if err != nil {
+		return err
+	}
+
+	if len(typeMap.PK) == 0 {
+		return fmt.Errorf("%w: %s", ErrNoPrimaryKey, typeMap.NonRefType.Name())
+	}
+
+	if len(keys) != len(typeMap.PK) {
+		return fmt.Errorf("%s expects %d primary key values, got %d", typeMap.NonRefType.Name(), len(typeMap.PK), len(keys))
+	}
+
+	queryConfig.Item = reflect.Zero(tdest.Elem()).Interface()
+	queryConfig.ColumnName = ""
+	queryConfig.Where = sqlstrings.And(queryConfig.Where, pkCondition(typeMap, keys))
+
+	return db.getWith(context, handler, queryConfig, dest)
+}
+
+// Подставляет item в queryConfig и добавляет к Where условие по значениям его первичного ключа
+func (db *DB) itemPKConfig(queryConfig sqlstrings.QueryConfig, item any) (sqlstrings.QueryConfig, error) {
+	if item == nil {
+		return queryConfig, ErrNilItem
+	}
+
+	typeMap, err := db.typeMapper().Map(reflect.TypeOf(item), queryConfig.TagName)
+	if err != nil {
+		return queryConfig, err
+	}
+
+	if len(typeMap.PK) == 0 {
+		return queryConfig, fmt.Errorf("%w: %s", ErrNoPrimaryKey, typeMap.NonRefType.Name())
+	}
+
+	val := reflect.ValueOf(item)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return queryConfig, ErrNilItem
+		}
+		val = val.Elem()
+	}
+
+	keys := make([]any, len(typeMap.PK))
+	for idx, fieldInfo := range typeMap.PK {
+		field := val.FieldByIndex(fieldInfo.Index)
+		if field.Kind() == reflect.Pointer && !field.IsNil() {
+			field = field.Elem()
+		}
+		keys[idx] = field.Interface()
+	}
+
+	queryConfig.Item = item
+	queryConfig.ColumnName = ""
+	queryConfig.Where = sqlstrings.And(queryConfig.Where, pkCondition(typeMap, keys))
+
+	return queryConfig, nil
+}
+
+// k1 = $x AND k2 = $y ...
+func pkCondition(typeMap *sqlreflect.TypeMap, keys []any) sqlstrings.Condition {
+	conditions := make([]sqlstrings.Condition, len(typeMap.PK))
+	for idx, fieldInfo := range typeMap.PK {
+		conditions[idx] = sqlstrings.Eq(fieldInfo.FTag, keys[idx])
+	}
+	return sqlstrings.And(conditions...)
+}
+
+//endregion
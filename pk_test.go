@@ -0,0 +1,74 @@
+package gosql
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type UserRoles struct {
+	UserId  int    `db:"UserId,pk"`
+	RoleId  int    `db:"RoleId,pk"`
+	Comment string `db:"Comment"`
+}
+
+func TestPrimaryKey(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rows: [][]any{{1, 2, "granted"}}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db"}
+
+	if _, err := db.UpdateItem(qc, &UserRoles{UserId: 1, RoleId: 2, Comment: "changed"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := db.DeleteItem(qc, UserRoles{UserId: 1, RoleId: 2}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var role UserRoles
+	if err := db.GetByPK(qc, &role, 1, 2); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"UPDATE UserRoles SET Comment = $1 WHERE UserId = $2 AND RoleId = $3",
+		"DELETE FROM UserRoles WHERE UserId = $1 AND RoleId = $2",
+		"SELECT UserId, RoleId, Comment FROM UserRoles WHERE UserId = $1 AND RoleId = $2",
+	}
+	for idx, query := range expected {
+		if handler.queries[idx] != query {
+			t.Errorf("query %d failed: %s", idx, handler.queries[idx])
+		}
+	}
+
+	if !slices.Equal(handler.args[0], []any{"changed", 1, 2}) || !slices.Equal(handler.args[2], []any{1, 2}) {
+		t.Errorf("args not match %v %v", handler.args[0], handler.args[2])
+	}
+
+	if role.Comment != "granted" {
+		t.Errorf("dest not filled %#v", role)
+	}
+
+	if err := db.GetByPK(qc, &role, 1); err == nil {
+		t.Errorf("expected error for the wrong number of keys")
+	}
+
+	// имя таблицы берется из типа и для указателя на структуру
+	if _, err := db.DeleteItem(qc, &UserRoles{UserId: 3, RoleId: 4}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if query := handler.queries[len(handler.queries)-1]; query != "DELETE FROM UserRoles WHERE UserId = $1 AND RoleId = $2" {
+		t.Errorf("delete by pointer failed: %s", query)
+	}
+	if args := handler.args[len(handler.args)-1]; !slices.Equal(args, []any{3, 4}) {
+		t.Errorf("delete by pointer args not match %v", args)
+	}
+
+	if _, err := db.DeleteItem(qc, Posts{Id: 1}); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Errorf("expected ErrNoPrimaryKey, got %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+//region Preload
+
+// Заполняет поля-связи (тег rel) записей среза dest, relations - имена полей-связей
+// Для каждой связи выполняется один запрос WHERE fk IN (...), для many2many два: к промежуточной таблице и к связанной
+// Из queryConfig используются TagName, NameWrapper и Dialect
+// ======================================================================================
+// Fills the relation fields (the rel tag) of the records of the dest slice, relations are the names of the relation fields
+// One WHERE fk IN (...) query is executed for every relation, two for many2many: to the link table and to the related one
+// TagName, NameWrapper and Dialect are used from queryConfig
+func (db *DB) Preload(queryConfig sqlstrings.QueryConfig, dest any, relations ...string) error {
+	return db.PreloadContext(context.Background(), queryConfig, dest, relations...)
+}
+
+func (db *DB) PreloadContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, relations ...string) error {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.preloadWith(context, db.handler, queryConfig, dest, relations...)
+}
+
+func (tx *Tx) Preload(queryConfig sqlstrings.QueryConfig, dest any, relations ...string) error {
+	return tx.PreloadContext(context.Background(), queryConfig, dest, relations...)
+}
+
+func (tx *Tx) PreloadContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, relations ...string) error {
+	return tx.db.preloadWith(context, tx.handler, queryConfig, dest, relations...)
+}
+
+func (db *DB) preloadWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, dest any, relations ...string) error {
+	queryConfig = db.prepareConfig(queryConfig)
+
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Pointer || destVal.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("%w: dest must be a pointer to slice", ErrInvalidDest)
+	}
+
+	slice := destVal.Elem()
+
+	typeMap, err := db.typeMapper().Map(slice.Type().Elem(), queryConfig.TagName)
+	if err != nil {
+		return err
+	}
+
+	// записи среза, nil указатели пропускаются
+	var owners []reflect.Value
+	for i := range slice.Len() {
+		owner := slice.Index(i)
+		for owner.Kind() == reflect.Pointer && !owner.IsNil() {
+			owner = owner.Elem()
+		}
+		if owner.Kind() == reflect.Struct {
+			owners = append(owners, owner)
+		}
+	}
+
+	for _, name := range relations {
+		idx := slices.IndexFunc(typeMap.Relations, func(r *sqlreflect.RelationInfo) bool { return r.Name == name })
+		if idx < 0 {
+			return errors.New("unknown relation " + name + " of " + typeMap.NonRefType.Name())
+		}
+
+		if len(owners) == 0 {
+			continue
+		}
+
+		relation := typeMap.Relations[idx]
+
+		switch relation.Kind {
+		case sqlreflect.HASMANY:
+			err = db.preloadHasMany(context, handler, queryConfig, typeMap, relation, owners)
+		case sqlreflect.BELONGSTO:
+			err = db.preloadBelongsTo(context, handler, queryConfig, typeMap, relation, owners)
+		case sqlreflect.MANY2MANY:
+			err = db.preloadMany2Many(context, handler, queryConfig, typeMap, relation, owners)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *DB) preloadHasMany(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, typeMap *sqlreflect.TypeMap, relation *sqlreflect.RelationInfo, owners []reflect.Value) error {
+	ownerKey, err := relationField(typeMap, relation.PK)
+	if err != nil {
+		return err
+	}
+
+	related, relatedMap, err := db.selectRelated(context, handler, queryConfig, relation, relation.FK, fieldKeys(owners, ownerKey))
+	if err != nil {
+		return err
+	}
+
+	relatedKey, err := relationField(relatedMap, relation.FK)
+	if err != nil {
+		return err
+	}
+
+	groups := map[any][]reflect.Value{}
+	for i := range related.Len() {
+		item := related.Index(i)
+		if key, ok := fieldKey(item, relatedKey); ok {
+			groups[key] = append(groups[key], item)
+		}
+	}
+
+	for _, owner := range owners {
+		key, _ := fieldKey(owner, ownerKey)
+		setSlice(owner.FieldByIndex(relation.Index), groups[key])
+	}
+
+	return nil
+}
+
+func (db *DB) preloadBelongsTo(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, typeMap *sqlreflect.TypeMap, relation *sqlreflect.RelationInfo, owners []reflect.Value) error {
+	ownerKey, err := relationField(typeMap, relation.FK)
+	if err != nil {
+		return err
+	}
+
+	related, relatedMap, err := db.selectRelated(context, handler, queryConfig, relation, relation.PK, fieldKeys(owners, ownerKey))
+	if err != nil {
+		return err
+	}
+
+	relatedKey, err := relationField(relatedMap, relation.PK)
+	if err != nil {
+		return err
+	}
+
+	byKey := map[any]reflect.Value{}
+	for i := range related.Len() {
+		item := related.Index(i)
+		if key, ok := fieldKey(item, relatedKey); ok {
+			byKey[key] = item
+		}
+	}
+
+	for _, owner := range owners {
+		key, ok := fieldKey(owner, ownerKey)
+		if !ok {
+			continue
+		}
+		if item, ok := byKey[key]; ok {
+			setValue(owner.FieldByIndex(relation.Index), item)
+		}
+	}
+
+	return nil
+}
+
+func (db *DB) preloadMany2Many(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, typeMap *sqlreflect.TypeMap, relation *sqlreflect.RelationInfo, owners []reflect.Value) error {
+	ownerKey, err := relationField(typeMap, relation.PK)
+	if err != nil {
+		return err
+	}
+
+	relatedTypeMap, err := db.typeMapper().Map(relation.Elem, queryConfig.TagName)
+	if err != nil {
+		return err
+	}
+
+	relatedKey, err := relationField(relatedTypeMap, relation.RefPK)
+	if err != nil {
+		return err
+	}
+
+	ownerKeys := fieldKeys(owners, ownerKey)
+	if len(ownerKeys) == 0 {
+		return nil
+	}
+
+	tagName := queryConfig.TagName
+	if len(tagName) == 0 {
+		tagName = sqlstrings.StdTagName
+	}
+
+	// строка промежуточной таблицы описывается структурой из двух полей с типами ключей
+	linkType := reflect.StructOf([]reflect.StructField{
+		{Name: "Owner", Type: sqlreflect.ConversionTypeToNonRefType(ownerKey.Ftype), Tag: reflect.StructTag(tagName + `:"` + relation.FK + `"`)},
+		{Name: "Ref", Type: sqlreflect.ConversionTypeToNonRefType(relatedKey.Ftype), Tag: reflect.StructTag(tagName + `:"` + relation.Ref + `"`)},
+	})
+
+	links := reflect.New(reflect.SliceOf(linkType))
+	if err := db.selectIn(context, handler, queryConfig, relation.Through, reflect.Zero(linkType).Interface(), relation.FK, ownerKeys, links); err != nil {
+		return err
+	}
+
+	var refKeys []any
+	seen := map[any]bool{}
+	for i := range links.Elem().Len() {
+		ref := keyOf(links.Elem().Index(i).Field(1))
+		if !seen[ref] {
+			seen[ref] = true
+			refKeys = append(refKeys, ref)
+		}
+	}
+
+	related, _, err := db.selectRelated(context, handler, queryConfig, relation, relation.RefPK, refKeys)
+	if err != nil {
+		return err
+	}
+
+	byKey := map[any]reflect.Value{}
+	for i := range related.Len() {
+		item := related.Index(i)
+		if key, ok := fieldKey(item, relatedKey); ok {
+			byKey[key] = item
+		}
+	}
+
+	groups := map[any][]reflect.Value{}
+	for i := range links.Elem().Len() {
+		link := links.Elem().Index(i)
+		if item, ok := byKey[keyOf(link.Field(1))]; ok {
+			owner := keyOf(link.Field(0))
+			groups[owner] = append(groups[owner], item)
+		}
+	}
+
+	for _, owner := range owners {
+		key, _ := fieldKey(owner, ownerKey)
+		setSlice(owner.FieldByIndex(relation.Index), groups[key])
+	}
+
+	return nil
+}
+
+// Выбирает записи связанной таблицы WHERE column IN (keys), возвращает срез значений типа relation.Elem
+func (db *DB) selectRelated(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, relation *sqlreflect.RelationInfo, column string, keys []any) (reflect.Value, *sqlreflect.TypeMap, error) {
+	related := reflect.New(reflect.SliceOf(relation.Elem)).Elem()
+
+	typeMap, err := db.typeMapper().Map(relation.Elem, queryConfig.TagName)
+	if err != nil {
+		return related, nil, err
+	}
+
+	if len(keys) == 0 {
+		return related, typeMap, nil
+	}
+
+	err = db.selectIn(context, handler, queryConfig, relation.Table, reflect.Zero(relation.Elem).Interface(), column, keys, related.Addr())
+
+	return related, typeMap, err
+}
+
+// Выбирает записи tableName WHERE column IN (keys) в dest - указатель на срез, ключи делятся на части по ограничению аргументов (SetMaxParams или диалект)
+func (db *DB) selectIn(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, tableName string, item any, column string, keys []any, dest reflect.Value) error {
+	limit := db.paramsLimit(db.prepareConfig(queryConfig).Dialect)
+
+	for start := 0; start < len(keys); start += limit {
+		end := min(start+limit, len(keys))
+
+		chunk := reflect.New(dest.Elem().Type())
+		if err := db.selectWith(context, handler, relatedConfig(queryConfig, tableName, item, column, keys[start:end]), chunk.Interface()); err != nil {
+			return err
+		}
+		dest.Elem().Set(reflect.AppendSlice(dest.Elem(), chunk.Elem()))
+	}
+
+	return nil
+}
+
+func relatedConfig(queryConfig sqlstrings.QueryConfig, tableName string, item any, column string, keys []any) sqlstrings.QueryConfig {
+	return sqlstrings.QueryConfig{
+		TableName:   tableName,
+		NameWrapper: queryConfig.NameWrapper,
+		TagName:     queryConfig.TagName,
+		Item:        item,
+		Dialect:     queryConfig.Dialect,
+		Where:       sqlstrings.In(column, keys...),
+	}
+}
+
+func relationField(typeMap *sqlreflect.TypeMap, column string) (*sqlreflect.FieldInfo, error) {
+	idx := slices.IndexFunc(typeMap.Fields, func(f *sqlreflect.FieldInfo) bool { return f.FTag == column })
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: column %s is not mapped in %s", sqlreflect.ErrInvalidRelation, column, typeMap.NonRefType.Name())
+	}
+	return typeMap.Fields[idx], nil
+}
+
+// Уникальные значения ключа записей в порядке появления
+func fieldKeys(items []reflect.Value, field *sqlreflect.FieldInfo) []any {
+	var keys []any
+	seen := map[any]bool{}
+	for _, item := range items {
+		key, ok := fieldKey(item, field)
+		if ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func fieldKey(item reflect.Value, field *sqlreflect.FieldInfo) (any, bool) {
+	value := item.FieldByIndex(field.Index)
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		return nil, false
+	}
+	return keyOf(value), true
+}
+
+// Приводит ключ к сравнимому виду, чтобы целые ключи разной ширины и знаковости (int, int64, uint) совпадали
+// ======================================================================================
+// Converts the key to a comparable form so that integer keys of different width and signedness (int, int64, uint) match
+func keyOf(value reflect.Value) any {
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// беззнаковый ключ совпадает со знаковым, если помещается в int64
+		if value.Uint() <= math.MaxInt64 {
+			return int64(value.Uint())
+		}
+		return value.Uint()
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return string(value.Bytes())
+		}
+	}
+
+	return value.Interface()
+}
+
+// Записывает items в поле-срез, элементы которого могут быть структурами или указателями на них
+func setSlice(field reflect.Value, items []reflect.Value) {
+	res := reflect.MakeSlice(field.Type(), 0, len(items))
+	for _, item := range items {
+		res = reflect.Append(res, convertRelated(item, field.Type().Elem()))
+	}
+	field.Set(res)
+}
+
+func setValue(field reflect.Value, item reflect.Value) {
+	field.Set(convertRelated(item, field.Type()))
+}
+
+func convertRelated(item reflect.Value, target reflect.Type) reflect.Value {
+	if target.Kind() != reflect.Pointer {
+		return item
+	}
+	ptr := reflect.New(item.Type())
+	ptr.Elem().Set(item)
+	return ptr
+}
+
+//endregion
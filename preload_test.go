@@ -0,0 +1,144 @@
+package gosql
+
+import (
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type Posts struct {
+	Id     int    `db:"Id"`
+	UserId int64  `db:"UserId"`
+	Title  string `db:"Title"`
+}
+
+type Authors struct {
+	Id    int      `db:"Id"`
+	Name  string   `db:"Name"`
+	Posts []Posts  `rel:"has_many,fk=UserId,table=Posts"`
+	Roles []*Roles `rel:"many2many,through=UserRoles,fk=UserId,ref=RoleId"`
+}
+
+type Comments struct {
+	Id     int    `db:"Id"`
+	PostId int    `db:"PostId"`
+	Post   *Posts `rel:"belongs_to"`
+}
+
+func TestPreload(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rowsQueue: [][][]any{
+		{{10, int64(1), "first post"}, {11, int64(1), "second post"}, {12, int64(2), "third post"}},
+		{{1, 100}, {2, 100}, {2, 101}},
+		{{100, "admin"}, {101, "user"}},
+	}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db"}
+
+	authors := []*Authors{{Id: 1, Name: "bob"}, {Id: 2, Name: "alice"}, {Id: 3, Name: "nobody"}}
+	if err := db.Preload(qc, &authors, "Posts", "Roles"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"SELECT Id, UserId, Title FROM Posts WHERE UserId IN ($1, $2, $3)",
+		"SELECT UserId, RoleId FROM UserRoles WHERE UserId IN ($1, $2, $3)",
+		"SELECT Id, Title FROM Roles WHERE Id IN ($1, $2)",
+	}
+	for idx, query := range expected {
+		if handler.queries[idx] != query {
+			t.Errorf("query %d failed: %s", idx, handler.queries[idx])
+		}
+	}
+
+	if len(authors[0].Posts) != 2 || len(authors[1].Posts) != 1 || authors[2].Posts == nil || len(authors[2].Posts) != 0 {
+		t.Errorf("has_many failed: %#v %#v %#v", authors[0].Posts, authors[1].Posts, authors[2].Posts)
+	}
+
+	if len(authors[0].Roles) != 1 || authors[0].Roles[0].Title != "admin" || len(authors[1].Roles) != 2 || authors[1].Roles[1].Title != "user" {
+		t.Errorf("many2many failed: %#v %#v", authors[0].Roles, authors[1].Roles)
+	}
+
+	handler.rowsQueue = [][][]any{{{10, int64(1), "first post"}}}
+
+	comments := []Comments{{Id: 1, PostId: 10}, {Id: 2, PostId: 10}, {Id: 3, PostId: 99}}
+	if err := db.Preload(qc, &comments, "Post"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if handler.queries[3] != "SELECT Id, UserId, Title FROM Posts WHERE Id IN ($1, $2)" {
+		t.Errorf("belongs_to query failed: %s", handler.queries[3])
+	}
+
+	if comments[0].Post == nil || comments[1].Post.Title != "first post" || comments[2].Post != nil {
+		t.Errorf("belongs_to failed: %#v", comments)
+	}
+
+	if err := db.Preload(qc, &comments, "Unknown"); err == nil {
+		t.Errorf("unknown relation must be rejected")
+	}
+}
+
+type UintPosts struct {
+	Id     int    `db:"Id"`
+	UserId uint   `db:"UserId"`
+	Title  string `db:"Title"`
+}
+
+type UintAuthors struct {
+	Id    int         `db:"Id"`
+	Posts []UintPosts `rel:"has_many,fk=UserId,table=Posts"`
+}
+
+func TestPreloadChunks(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rowsQueue: [][][]any{
+		{{10, int64(1), "first post"}, {12, int64(2), "third post"}},
+		{{13, int64(3), "fourth post"}},
+	}}
+	db.ChangeHandler(handler)
+	db.SetMaxParams(2)
+
+	qc := sqlstrings.QueryConfig{TagName: "db"}
+
+	// ключи делятся на части по ограничению аргументов, результаты объединяются
+	authors := []*Authors{{Id: 1}, {Id: 2}, {Id: 3}}
+	if err := db.Preload(qc, &authors, "Posts"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		"SELECT Id, UserId, Title FROM Posts WHERE UserId IN ($1, $2)",
+		"SELECT Id, UserId, Title FROM Posts WHERE UserId IN ($1)",
+	}
+	if len(handler.queries) != len(expected) {
+		t.Fatalf("wrong number of chunks: %v", handler.queries)
+	}
+	for idx, query := range expected {
+		if handler.queries[idx] != query {
+			t.Errorf("query %d failed: %s", idx, handler.queries[idx])
+		}
+	}
+
+	if len(handler.args[0]) != 2 || len(handler.args[1]) != 1 {
+		t.Errorf("wrong chunk args: %v", handler.args)
+	}
+
+	if len(authors[0].Posts) != 1 || len(authors[1].Posts) != 1 || len(authors[2].Posts) != 1 || authors[2].Posts[0].Title != "fourth post" {
+		t.Errorf("chunked preload failed: %#v %#v %#v", authors[0].Posts, authors[1].Posts, authors[2].Posts)
+	}
+
+	// беззнаковый внешний ключ совпадает со знаковым первичным
+	db.SetMaxParams(0)
+	handler.rowsQueue = [][][]any{{{10, uint(1), "first post"}, {11, uint(2), "second post"}}}
+
+	uintAuthors := []UintAuthors{{Id: 1}, {Id: 2}}
+	if err := db.Preload(qc, &uintAuthors, "Posts"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(uintAuthors[0].Posts) != 1 || len(uintAuthors[1].Posts) != 1 || uintAuthors[1].Posts[0].Id != 11 {
+		t.Errorf("unsigned keys must match signed ones: %#v", uintAuthors)
+	}
+}
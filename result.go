@@ -0,0 +1,140 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Обработчик, который возвращает полный результат выполнения запроса (количество затронутых строк и последний добавленный Id), StdDbHandler реализует этот интерфейс
+// ======================================================================================
+// Handler that returns the full result of the query execution (the number of affected rows and the last inserted Id), StdDbHandler implements this interface
+type ResultHandler interface {
+	ExecResultContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error)
+}
+
+//region Handlers Realization
+
+func (stdh *StdDbHandler) ExecResultContext(context context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	stdh.dbMutex.RLock()
+	defer stdh.dbMutex.RUnlock()
+
+	return stdh.db.ExecContext(context, query, args...)
+}
+
+func (stdt *StdTxHandler) ExecResultContext(context context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return stdt.tx.ExecContext(context, query, args...)
+}
+
+//endregion
+
+//region DB Results
+
+// Как Exec, но возвращает sql.Result, если обработчик не реализует ResultHandler то LastInsertId результата возвращает ошибку
+// Exec, Update и Delete, возвращающие int, оставлены для совместимости, новый код должен использовать методы *Result
+// ======================================================================================
+// Same as Exec but returns sql.Result, if the handler does not implement ResultHandler then LastInsertId of the result returns an error
+// Exec, Update and Delete returning int are kept for compatibility, new code should use the *Result methods
+func (db *DB) ExecResult(query string, args ...any) (sql.Result, error) {
+	return db.ExecResultContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExecResultContext(context context.Context, query string, args ...any) (sql.Result, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.execResultWith(context, db.handler, query, db.prepareConfig(sqlstrings.QueryConfig{}), args...)
+}
+
+// Как Update, но возвращает sql.Result, а не количество строк / Same as Update but returns sql.Result instead of the number of rows
+func (db *DB) UpdateResult(queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return db.UpdateResultContext(context.Background(), queryConfig, args...)
+}
+
+func (db *DB) UpdateResultContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.updateResultWith(context, db.handler, queryConfig, args...)
+}
+
+// Как Delete, но возвращает sql.Result, а не количество строк / Same as Delete but returns sql.Result instead of the number of rows
+func (db *DB) DeleteResult(queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return db.DeleteResultContext(context.Background(), queryConfig, args...)
+}
+
+func (db *DB) DeleteResultContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.deleteResultWith(context, db.handler, queryConfig, args...)
+}
+
+func (tx *Tx) ExecResult(query string, args ...any) (sql.Result, error) {
+	return tx.ExecResultContext(context.Background(), query, args...)
+}
+
+func (tx *Tx) ExecResultContext(context context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.db.execResultWith(context, tx.handler, query, tx.db.prepareConfig(sqlstrings.QueryConfig{}), args...)
+}
+
+func (tx *Tx) UpdateResult(queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return tx.UpdateResultContext(context.Background(), queryConfig, args...)
+}
+
+func (tx *Tx) UpdateResultContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return tx.db.updateResultWith(context, tx.handler, queryConfig, args...)
+}
+
+func (tx *Tx) DeleteResult(queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return tx.DeleteResultContext(context.Background(), queryConfig, args...)
+}
+
+func (tx *Tx) DeleteResultContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return tx.db.deleteResultWith(context, tx.handler, queryConfig, args...)
+}
+
+func (db *DB) updateResultWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	queryConfig = db.prepareConfig(queryConfig)
+	context = withOperation(context, OPUPDATE)
+
+	query, args, err := db.updateQueryArgs(queryConfig, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.execResultWith(context, handler, query, queryConfig, args...)
+}
+
+func (db *DB) deleteResultWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	queryConfig = db.prepareConfig(queryConfig)
+	context = withOperation(context, OPDELETE)
+
+	query, args, err := deleteQueryArgs(queryConfig, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.execResultWith(context, handler, query, queryConfig, args...)
+}
+
+// Выполняет запрос через ResultHandler, если обработчик его не реализует то количество строк из ExecContext оборачивается в driver.RowsAffected
+// ======================================================================================
+// Executes the query via ResultHandler, if the handler does not implement it the number of rows from ExecContext is wrapped into driver.RowsAffected
+func (db *DB) execResultWith(context context.Context, handler DbHandler, query string, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	if resultHandler, ok := handler.(ResultHandler); ok {
+		res, err := resultHandler.ExecResultContext(context, query, queryConfig, args...)
+		return res, wrapQueryError(err, query, queryConfig)
+	}
+
+	affected, err := handler.ExecContext(context, query, queryConfig, args...)
+	if err != nil {
+		return nil, wrapQueryError(err, query, queryConfig)
+	}
+
+	return driver.RowsAffected(affected), nil
+}
+
+//endregion
@@ -0,0 +1,104 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type fakeResult struct {
+	lastId   int64
+	affected int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.lastId, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+// соединение без RETURNING, QueryRowContext вызываться не должен
+type fakeConn struct {
+	queries []string
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ ...any) (*sql.Rows, error) {
+	panic("unexpected QueryContext " + query)
+}
+
+func (c *fakeConn) QueryRowContext(_ context.Context, query string, _ ...any) *sql.Row {
+	panic("unexpected QueryRowContext " + query)
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ ...any) (sql.Result, error) {
+	c.queries = append(c.queries, query)
+	return fakeResult{lastId: 42, affected: 1}, nil
+}
+
+type fakeResultHandler struct {
+	fakeHandler
+}
+
+func (f *fakeResultHandler) ExecResultContext(_ context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, args)
+	return fakeResult{lastId: 7, affected: 3}, nil
+}
+
+func TestLastInsertIdFallback(t *testing.T) {
+	conn := &fakeConn{}
+	qc := sqlstrings.QueryConfig{TagName: "db", Item: Devices{Name: "router"}, Dialect: sqlstrings.MYSQL}
+
+	id, err := insertConn(context.Background(), conn, "INSERT INTO Devices (Name) VALUES (?)", qc, "router")
+	if err != nil || id != 42 {
+		t.Errorf("LastInsertId not used: %d %v", id, err)
+	}
+
+	// сгенерированному запросу без возвращаемых столбцов достаточно Exec
+	qc = sqlstrings.QueryConfig{TagName: "db", Item: Posts{Title: "title"}, Dialect: sqlstrings.POSTGRES}
+	if _, err := insertConn(context.Background(), conn, "INSERT INTO Posts (Id, UserId, Title) VALUES ($1,$2,$3)", qc, 1, 1, "title"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if len(conn.queries) != 2 {
+		t.Errorf("queries not executed %v", conn.queries)
+	}
+}
+
+func TestExecResult(t *testing.T) {
+	db := GetDb(nil, "test")
+	db.ChangeHandler(&fakeHandler{execRes: 5})
+
+	qc := sqlstrings.QueryConfig{TagName: "db", Item: Posts{Id: 1, Title: "title"}, ColumnName: "Id"}
+
+	res, err := db.UpdateResult(qc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected != 5 {
+		t.Errorf("rows affected not match %d", affected)
+	}
+
+	if _, err := res.LastInsertId(); err == nil {
+		t.Errorf("expected error for the handler without ResultHandler")
+	}
+
+	handler := &fakeResultHandler{}
+	db.ChangeHandler(handler)
+
+	res, err = db.DeleteResult(qc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	lastId, _ := res.LastInsertId()
+	affected, _ := res.RowsAffected()
+	if lastId != 7 || affected != 3 || handler.queries[0] != "DELETE FROM Posts WHERE Id = $1" {
+		t.Errorf("result not match %d %d %v", lastId, affected, handler.queries)
+	}
+}
@@ -0,0 +1,255 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// SQLSTATE ошибок, после которых запрос можно повторить: 40001 - ошибка сериализации, 40P01 - взаимоблокировка (Postgres)
+// ======================================================================================
+// SQLSTATE codes of errors after which the query can be repeated: 40001 - serialization failure, 40P01 - deadlock detected (Postgres)
+var RetryableSQLStates = []string{"40001", "40P01"}
+
+// MaxAttempts - максимальное количество попыток, включая первую, значения меньше 2 отключают повторы ;
+// BaseDelay - задержка перед второй попыткой, каждая следующая задержка удваивается ;
+// MaxDelay - верхняя граница задержки, 0 - без ограничения ;
+// Classifier - можно ли повторить запрос после ошибки, nil - DefaultRetryClassifier ;
+// RetryNonIdempotent - повторять также вставки и запросы Exec, которые по умолчанию не повторяются ;
+// ======================================================================================
+// MaxAttempts - maximum number of attempts including the first one, values less than 2 disable retries ;
+// BaseDelay - delay before the second attempt, every next delay is doubled ;
+// MaxDelay - upper bound of the delay, 0 means no limit ;
+// Classifier - whether the query can be repeated after the error, nil means DefaultRetryClassifier ;
+// RetryNonIdempotent - also retry inserts and Exec queries that are not retried by default ;
+type RetryPolicy struct {
+	MaxAttempts        int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	Classifier         func(err error) bool
+	RetryNonIdempotent bool
+}
+
+// Возвращает политику по умолчанию: 3 попытки, задержка от 10ms до 1s, DefaultRetryClassifier
+// ======================================================================================
+// Returns the default policy: 3 attempts, the delay from 10ms to 1s, DefaultRetryClassifier
+func GetRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    time.Second,
+		Classifier:  DefaultRetryClassifier,
+	}
+}
+
+// Возвращает SQLSTATE ошибки драйвера, если ошибка в цепочке реализует метод SQLState() string (pgx, lib/pq)
+// ======================================================================================
+// Returns the SQLSTATE of the driver error if an error in the chain implements the SQLState() string method (pgx, lib/pq)
+func SQLState(err error) (string, bool) {
+	var stater interface{ SQLState() string }
+	if errors.As(err, &stater) {
+		return stater.SQLState(), true
+	}
+	return "", false
+}
+
+// Возвращает классификатор, который считает повторяемыми ошибки с кодами codes, extract достает код из ошибки конкретного драйвера
+// ======================================================================================
+// Returns a classifier that considers the errors with the codes retryable, extract gets the code from the error of the specific driver
+func GetSQLStateClassifier(extract func(err error) (string, bool), codes ...string) func(err error) bool {
+	return func(err error) bool {
+		code, ok := extract(err)
+		return ok && slices.Contains(codes, code)
+	}
+}
+
+var defaultSQLStateClassifier = GetSQLStateClassifier(SQLState, RetryableSQLStates...)
+
+// Повторяемые ошибки по умолчанию: driver.ErrBadConn и ошибки с кодами RetryableSQLStates
+// ======================================================================================
+// Retryable errors by default: driver.ErrBadConn and the errors with the RetryableSQLStates codes
+func DefaultRetryClassifier(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || defaultSQLStateClassifier(err)
+}
+
+// Задержка перед попыткой attempt (начиная с 1 для первого повтора): половина экспоненциальной задержки плюс случайная часть второй половины
+// ======================================================================================
+// Delay before the attempt (starting from 1 for the first retry): half of the exponential delay plus a random part of the other half
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 {
+		delay = min(delay, p.MaxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Classifier == nil {
+		return DefaultRetryClassifier(err)
+	}
+	return p.Classifier(err)
+}
+
+// Устанавливает политику повторов запросов DB и замыканий WithTx, nil отключает повторы
+// Запросы внутри Tx не повторяются, т.к. после ошибки транзакция обычно прервана, повторяется вся транзакция в WithTx
+// ======================================================================================
+// Sets the retry policy of DB queries and WithTx closures, nil disables retries
+// Queries inside Tx are not retried because the transaction is usually aborted after the error, the whole transaction is retried in WithTx
+func (db *DB) SetRetryPolicy(policy *RetryPolicy) {
+	db.retries.Store(policy)
+}
+
+func (db *DB) RetryPolicy() *RetryPolicy {
+	return db.retries.Load()
+}
+
+// Выполняет call, пока он возвращает повторяемую ошибку и есть попытки, между попытками ждет с учетом отмены контекста
+func (db *DB) retry(context context.Context, idempotent bool, call func() error) error {
+	policy := db.retries.Load()
+
+	err := call()
+	if policy == nil || (!idempotent && !policy.RetryNonIdempotent) {
+		return err
+	}
+
+	for attempt := 1; attempt < policy.MaxAttempts && err != nil && policy.retryable(err); attempt++ {
+		// select выбирает случайно, если готовы и таймер и контекст, поэтому отмена проверяется заранее
+		if context.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(policy.Delay(attempt))
+		select {
+		case <-context.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		err = call()
+	}
+
+	return err
+}
+
+//region Retry Handler
+
+// Обертка обработчика DB, повторяет обращения по политике DB, идемпотентность определяется по операции из контекста
+// ======================================================================================
+// Wrapper of the DB handler, it repeats calls by the DB policy, idempotency is determined by the operation from the context
+type retryHandler struct {
+	next DbHandler
+	db   *DB
+}
+
+// Оборачивает обработчик DB в middleware и повторы, обработчики транзакций оборачиваются только в middleware
+// ======================================================================================
+// Wraps the DB handler into the middlewares and retries, transaction handlers are wrapped only into the middlewares
+func (db *DB) retryWrap(handler DbHandler) DbHandler {
+	return &retryHandler{next: db.wrapHandler(handler), db: db}
+}
+
+func (h *retryHandler) Unwrap() DbHandler {
+	return h.next
+}
+
+func idempotent(context context.Context, fallback Operation) bool {
+	op, ok := OperationFromContext(context)
+	if !ok {
+		op = fallback
+	}
+	return op != OPINSERT && op != OPEXEC
+}
+
+func (h *retryHandler) SelectContext(context context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	// при повторе убираются записи, добавленные неудачной попыткой
+	reset := destReset(dest)
+
+	return h.db.retry(context, idempotent(context, OPSELECT), func() error {
+		reset()
+		return h.next.SelectContext(context, dest, query, queryConfig, args...)
+	})
+}
+
+// Возвращает функцию, которая возвращает срез dest к текущей длине, чтобы повторный Select не дублировал записи
+// ======================================================================================
+// Returns a function that truncates the dest slice back to its current length so that a repeated Select does not duplicate records
+func destReset(dest any) func() {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Pointer || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return func() {}
+	}
+
+	slice = slice.Elem()
+	length := slice.Len()
+
+	return func() {
+		if slice.Len() > length {
+			slice.SetLen(length)
+		}
+	}
+}
+
+func (h *retryHandler) InsertContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (id int, err error) {
+	err = h.db.retry(context, idempotent(context, OPINSERT), func() error {
+		id, err = h.next.InsertContext(context, query, queryConfig, args...)
+		return err
+	})
+	return id, err
+}
+
+func (h *retryHandler) ExecContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (res int, err error) {
+	err = h.db.retry(context, idempotent(context, OPEXEC), func() error {
+		res, err = h.next.ExecContext(context, query, queryConfig, args...)
+		return err
+	})
+	return res, err
+}
+
+// Повторяется только открытие строк, ошибки при чтении строк не повторяются
+// ======================================================================================
+// Only opening of the rows is retried, errors while reading the rows are not retried
+func (h *retryHandler) QueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (rows ResultRows, err error) {
+	err = h.db.retry(context, idempotent(context, OPSELECT), func() error {
+		rows, err = queryNext(context, h.next, query, queryConfig, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (h *retryHandler) ExecResultContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (res sql.Result, err error) {
+	err = h.db.retry(context, idempotent(context, OPEXEC), func() error {
+		res, err = execResultNext(context, h.next, query, queryConfig, args...)
+		return err
+	})
+	return res, err
+}
+
+func (h *retryHandler) Select(dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return h.SelectContext(context.Background(), dest, query, queryConfig, args...)
+}
+
+func (h *retryHandler) Insert(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.InsertContext(context.Background(), query, queryConfig, args...)
+}
+
+func (h *retryHandler) Exec(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.ExecContext(context.Background(), query, queryConfig, args...)
+}
+
+//endregion
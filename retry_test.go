@@ -0,0 +1,180 @@
+package gosql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type sqlStateError struct {
+	code string
+}
+
+func (e *sqlStateError) Error() string {
+	return "sqlstate " + e.code
+}
+
+func (e *sqlStateError) SQLState() string {
+	return e.code
+}
+
+// Возвращает err первые failures обращений
+type flakyHandler struct {
+	*fakeHandler
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyHandler) fail() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flakyHandler) SelectContext(context context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	if err := f.fakeHandler.SelectContext(context, dest, query, queryConfig, args...); err != nil {
+		return err
+	}
+	return f.fail()
+}
+
+func (f *flakyHandler) InsertContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	f.fakeHandler.InsertContext(context, query, queryConfig, args...)
+	return 1, f.fail()
+}
+
+func (f *flakyHandler) ExecContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	f.fakeHandler.ExecContext(context, query, queryConfig, args...)
+	return 1, f.fail()
+}
+
+func TestRetryClassifier(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected bool
+	}{
+		{&sqlStateError{code: "40001"}, true},
+		{&QueryError{Query: "SELECT 1", Err: &sqlStateError{code: "40P01"}}, true},
+		{&sqlStateError{code: "23505"}, false},
+		{driver.ErrBadConn, true},
+		{errors.New("boom"), false},
+	}
+
+	for _, c := range cases {
+		if DefaultRetryClassifier(c.err) != c.expected {
+			t.Errorf("wrong classification of %v", c.err)
+		}
+	}
+
+	mysqlDeadlock := GetSQLStateClassifier(func(err error) (string, bool) {
+		return err.Error(), true
+	}, "1213")
+	if !mysqlDeadlock(errors.New("1213")) || mysqlDeadlock(errors.New("1062")) {
+		t.Errorf("custom classifier does not work")
+	}
+
+	policy := &RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	for attempt, expected := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 10: 50 * time.Millisecond} {
+		if delay := policy.Delay(attempt); delay < expected/2 || delay > expected {
+			t.Errorf("delay %s of attempt %d is out of [%s, %s]", delay, attempt, expected/2, expected)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	db := GetDb(nil, "main")
+	handler := &flakyHandler{fakeHandler: &fakeHandler{rows: [][]any{{1, "admin"}}, columns: []string{"Id", "Title"}}, failures: 2, err: &sqlStateError{code: "40001"}}
+	db.ChangeHandler(handler)
+
+	policy := GetRetryPolicy()
+	policy.BaseDelay = time.Microsecond
+	db.SetRetryPolicy(policy)
+
+	var roles []Roles
+	if err := db.Select(sqlstrings.QueryConfig{TableName: "Roles", TagName: "db", Item: Roles{}}, &roles); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if handler.calls != 3 || len(roles) != 1 {
+		t.Errorf("select must be retried without duplicates: calls %d, rows %d", handler.calls, len(roles))
+	}
+
+	// вставки не повторяются без RetryNonIdempotent
+	handler.calls = 0
+	if _, err := db.Insert(sqlstrings.QueryConfig{TableName: "Roles", Item: Roles{Title: "user"}}); err == nil || handler.calls != 1 {
+		t.Errorf("insert must not be retried: calls %d, err %v", handler.calls, err)
+	}
+
+	handler.calls = 0
+	if _, err := db.Update(sqlstrings.QueryConfig{TableName: "Roles", Item: Roles{Title: "user"}}); err != nil || handler.calls != 3 {
+		t.Errorf("update must be retried: calls %d, err %v", handler.calls, err)
+	}
+
+	policy.RetryNonIdempotent = true
+	handler.calls = 0
+	if _, err := db.Insert(sqlstrings.QueryConfig{TableName: "Roles", Item: Roles{Title: "user"}}); err != nil || handler.calls != 3 {
+		t.Errorf("insert must be retried with RetryNonIdempotent: calls %d, err %v", handler.calls, err)
+	}
+
+	// попытки ограничены MaxAttempts
+	handler.calls = 0
+	handler.failures = 10
+	if _, err := db.Exec("DELETE FROM Roles"); err == nil || handler.calls != 3 {
+		t.Errorf("attempts must be limited: calls %d, err %v", handler.calls, err)
+	}
+
+	// неповторяемая ошибка возвращается сразу
+	handler.calls = 0
+	handler.err = errors.New("boom")
+	if _, err := db.Exec("DELETE FROM Roles"); err == nil || handler.calls != 1 {
+		t.Errorf("non retryable error must not be retried: calls %d, err %v", handler.calls, err)
+	}
+
+	db.SetRetryPolicy(nil)
+	handler.calls = 0
+	handler.err = &sqlStateError{code: "40001"}
+	if _, err := db.Exec("DELETE FROM Roles"); err == nil || handler.calls != 1 {
+		t.Errorf("retries must be disabled: calls %d, err %v", handler.calls, err)
+	}
+}
+
+func TestRetryTx(t *testing.T) {
+	db := GetDb(nil, "main")
+	db.ChangeHandler(&fakeBeginner{})
+
+	policy := GetRetryPolicy()
+	policy.BaseDelay = time.Microsecond
+	db.SetRetryPolicy(policy)
+
+	serialization := &sqlStateError{code: "40001"}
+
+	calls := 0
+	err := db.WithTx(context.Background(), func(tx *Tx) error {
+		calls++
+		if calls < 3 {
+			return serialization
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("transaction must be retried: calls %d, err %v", calls, err)
+	}
+
+	// отмененный контекст прекращает повторы
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = db.WithTx(ctx, func(tx *Tx) error {
+		calls++
+		cancel()
+		return serialization
+	})
+	if !errors.Is(err, serialization) || calls != 1 {
+		t.Errorf("retries must stop on cancelled context: calls %d, err %v", calls, err)
+	}
+}
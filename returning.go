@@ -0,0 +1,180 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+//region Returning
+
+// Диалект не поддерживает RETURNING / The dialect does not support RETURNING
+var ErrReturningNotSupported = errors.New("dialect does not support RETURNING")
+
+// Добавляет запись и записывает возвращенные столбцы (queryConfig.Returning, ColumnName или столбец с опцией auto) в dest
+// dest - указатель на структуру, поля которой сопоставляются со столбцами по тегам, или указатель на значение для одного столбца (UUID, string, int64 ...)
+// ======================================================================================
+// Inserts a record and writes the returned columns (queryConfig.Returning, ColumnName or the column with the auto option) into dest
+// dest is a pointer to the struct whose fields are matched with the columns by tags, or a pointer to a value for a single column (UUID, string, int64 ...)
+func (db *DB) InsertScan(queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return db.InsertScanContext(context.Background(), queryConfig, dest, args...)
+}
+
+func (db *DB) InsertScanContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.insertScanWith(context, db.handler, queryConfig, dest, args...)
+}
+
+func (tx *Tx) InsertScan(queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.InsertScanContext(context.Background(), queryConfig, dest, args...)
+}
+
+func (tx *Tx) InsertScanContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.db.insertScanWith(context, tx.handler, queryConfig, dest, args...)
+}
+
+func (db *DB) insertScanWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	queryConfig = db.prepareConfig(queryConfig)
+	context = withOperation(context, OPINSERT)
+
+	tdest := reflect.TypeOf(dest)
+	if tdest == nil || tdest.Kind() != reflect.Pointer {
+		return fmt.Errorf("%w: dest must be a pointer", ErrInvalidDest)
+	}
+
+	query, args, err := db.insertQueryArgs(queryConfig, args...)
+	if err != nil {
+		return err
+	}
+
+	columns := queryConfig.ReturningColumns()
+	if len(columns) == 0 {
+		return errors.New("queryConfig parameter Returning or ColumnName must be specified")
+	}
+
+	rowsHandler, err := returningHandler(handler, queryConfig)
+	if err != nil {
+		return err
+	}
+
+	var pointers []any
+	if elem := tdest.Elem(); elem.Kind() == reflect.Struct && !sqlreflect.IsScannable(elem) {
+		typeMap, err := db.typeMapper().Map(elem, queryConfig.TagName)
+		if err != nil {
+			return err
+		}
+		pointers = sqlreflect.GetColumnsPointersOfItem(reflect.ValueOf(dest), typeMap, columns)
+	} else {
+		// первый столбец записывается в dest, остальные отбрасываются
+		pointers = sqlreflect.GetColumnsPointersOfItem(reflect.Value{}, nil, columns)
+		pointers[0] = dest
+	}
+
+	rows, err := rowsHandler.QueryContext(context, query, queryConfig, args...)
+	if err != nil {
+		return wrapQueryError(err, query, queryConfig)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return wrapQueryError(err, query, queryConfig)
+		}
+		return ErrNoRows
+	}
+
+	return wrapQueryError(rows.Scan(pointers...), query, queryConfig)
+}
+
+// Выполняет запрос с RETURNING, первая строка записывается в поля queryConfig.Item если это указатель на структуру
+// Возвращает значения столбца queryConfig.ReturningColumn() по строкам, 0 если он не возвращается или не является целым числом
+// ======================================================================================
+// Executes the query with RETURNING, the first row is written into the fields of queryConfig.Item if it is a pointer to the struct
+// Returns the values of the queryConfig.ReturningColumn() column by rows, 0 if it is not returned or is not an integer
+func (db *DB) returningWith(context context.Context, handler DbHandler, query string, queryConfig sqlstrings.QueryConfig, args ...any) ([]int, error) {
+	rowsHandler, err := returningHandler(handler, queryConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	item := reflect.ValueOf(queryConfig.Item)
+	return db.queryReturning(context, rowsHandler, query, queryConfig, func(row int) reflect.Value {
+		if row == 0 {
+			return item
+		}
+		return reflect.Value{}
+	}, args...)
+}
+
+// Сканирует все столбцы queryConfig.ReturningColumns() каждой строки в поля item(row) и собирает значения столбца queryConfig.ReturningColumn()
+func (db *DB) queryReturning(context context.Context, rowsHandler RowsHandler, query string, queryConfig sqlstrings.QueryConfig, item func(row int) reflect.Value, args ...any) ([]int, error) {
+	typeMap, err := db.typeMapper().Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := queryConfig.ReturningColumns()
+	idIdx := slices.Index(columns, queryConfig.ReturningColumn())
+
+	rows, err := rowsHandler.QueryContext(context, query, queryConfig, args...)
+	if err != nil {
+		return nil, wrapQueryError(err, query, queryConfig)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for row := 0; rows.Next(); row++ {
+		pointers := sqlreflect.GetColumnsPointersOfItem(item(row), typeMap, columns)
+		if err := rows.Scan(pointers...); err != nil {
+			return ids, wrapQueryError(err, query, queryConfig)
+		}
+
+		id := 0
+		if idIdx >= 0 {
+			id = intValue(reflect.ValueOf(pointers[idIdx]))
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, wrapQueryError(rows.Err(), query, queryConfig)
+}
+
+// Возвращает целое значение, на которое указывает v, 0 для остальных типов
+func intValue(v reflect.Value) int {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.CanInt():
+		return int(v.Int())
+	case v.CanUint():
+		return int(v.Uint())
+	}
+	return 0
+}
+
+func returningHandler(handler DbHandler, queryConfig sqlstrings.QueryConfig) (RowsHandler, error) {
+	if !queryConfig.Dialect.SupportsReturning() {
+		return nil, ErrReturningNotSupported
+	}
+
+	rowsHandler, ok := handler.(RowsHandler)
+	if !ok {
+		return nil, errors.New("handler does not support row iteration")
+	}
+
+	return rowsHandler, nil
+}
+
+//endregion
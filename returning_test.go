@@ -0,0 +1,129 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type Devices struct {
+	Id      string `db:"Id,pk,auto"`
+	Name    string `db:"Name"`
+	Version int    `db:"Version,readonly"`
+}
+
+func TestReturning(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rows: [][]any{{"0b6f5c1e", 1}}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db", Dialect: sqlstrings.POSTGRES, Returning: []string{"Id", "Version"}}
+
+	device := &Devices{Name: "router"}
+	id, err := db.Insert(qc.ChangeItem(device))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// строковый ключ не может быть возвращен как int
+	if id != 0 || device.Id != "0b6f5c1e" || device.Version != 1 {
+		t.Errorf("returned values not scanned %d %#v", id, device)
+	}
+
+	if handler.queries[0] != `INSERT INTO "Devices" ("Name") VALUES ($1) RETURNING "Id", "Version"` {
+		t.Errorf("query failed: %s", handler.queries[0])
+	}
+
+	handler.rows = [][]any{{2}}
+	device.Name = "switch"
+	if _, err := db.UpdateItem(qc.ChangeReturning("Version"), device); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if handler.queries[1] != `UPDATE "Devices" SET "Name" = $1 WHERE "Id" = $2 RETURNING "Version"` || device.Version != 2 {
+		t.Errorf("update returning failed: %s %#v", handler.queries[1], device)
+	}
+
+	// ключ возвращается через столбец с опцией auto
+	handler.rows = [][]any{{"5d1f9a07"}}
+	key, err := InsertReturning[string](context.Background(), db, qc.ChangeReturning().ChangeItem(Devices{Name: "modem"}))
+	if err != nil || key != "5d1f9a07" {
+		t.Errorf("InsertReturning failed: %q %v", key, err)
+	}
+
+	if handler.queries[2] != `INSERT INTO "Devices" ("Name") VALUES ($1) RETURNING "Id"` {
+		t.Errorf("query failed: %s", handler.queries[2])
+	}
+
+	if _, err := db.Insert(qc.ChangeItem(device).ChangeDialect(sqlstrings.MYSQL)); !errors.Is(err, ErrReturningNotSupported) {
+		t.Errorf("expected ErrReturningNotSupported, got %v", err)
+	}
+}
+
+type Counters struct {
+	Id      int    `db:"Id,pk,auto"`
+	Name    string `db:"Name"`
+	Version int    `db:"Version,readonly"`
+}
+
+func TestReturningId(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{rows: [][]any{{7, 1}}}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{TagName: "db", Dialect: sqlstrings.POSTGRES, Returning: []string{"Id", "Version"}}
+
+	counter := &Counters{Name: "a"}
+	id, err := db.Insert(qc.ChangeItem(counter))
+	if err != nil || id != 7 || counter.Id != 7 || counter.Version != 1 {
+		t.Errorf("Insert must return the id: %d %v %#v", id, err, counter)
+	}
+
+	// InsertMany сканирует все столбцы Returning и возвращает ключи
+	handler.rows = [][]any{{8, 1}, {9, 1}}
+	items := []*Counters{{Name: "b"}, {Name: "c"}}
+	ids, err := db.InsertMany(qc, items)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(ids) != 2 || ids[0] != 8 || ids[1] != 9 || items[0].Id != 8 || items[1].Version != 1 {
+		t.Errorf("InsertMany returning failed: %v %#v %#v", ids, items[0], items[1])
+	}
+
+	if handler.queries[1] != `INSERT INTO "Counters" ("Name") VALUES ($1),($2) RETURNING "Id", "Version"` {
+		t.Errorf("query failed: %s", handler.queries[1])
+	}
+
+	// элементы-значения не изменяются, но ключи возвращаются
+	ids, err = db.InsertMany(qc, []Counters{{Name: "d"}, {Name: "e"}})
+	if err != nil || len(ids) != 2 || ids[0] != 8 {
+		t.Errorf("InsertMany with values failed: %v %v", ids, err)
+	}
+
+	// Upsert с несколькими столбцами Returning
+	handler.rows = [][]any{{10, 3}}
+	upsertConfig := qc.ChangeOnConflict(sqlstrings.OnConflict{Columns: []string{"Name"}})
+	counter = &Counters{Name: "a"}
+	id, err = db.Upsert(upsertConfig.ChangeItem(counter))
+	if err != nil || id != 10 || counter.Id != 10 || counter.Version != 3 {
+		t.Errorf("Upsert returning failed: %d %v %#v", id, err, counter)
+	}
+
+	// без ключа среди столбцов Returning значения все равно записываются в Item
+	handler.rows = [][]any{{4}}
+	counter = &Counters{Name: "a"}
+	id, err = db.Upsert(upsertConfig.ChangeReturning("Version").ChangeItem(counter))
+	if err != nil || id != 0 || counter.Version != 4 {
+		t.Errorf("Upsert without key failed: %d %v %#v", id, err, counter)
+	}
+
+	// при DoNothing строка может не вернуться
+	handler.rows = nil
+	id, err = db.Upsert(upsertConfig.ChangeOnConflict(sqlstrings.OnConflict{Columns: []string{"Name"}, DoNothing: true}).ChangeItem(&Counters{Name: "a"}))
+	if err != nil || id != 0 {
+		t.Errorf("skipped row must not be an error: %d %v", id, err)
+	}
+}
@@ -0,0 +1,341 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Способ выбора реплики для чтения / Way of choosing the replica for reading
+type RoutingStrategy int
+
+const (
+	ROUNDROBIN RoutingStrategy = iota
+	LEASTLATENCY
+)
+
+func (s RoutingStrategy) String() string {
+	if s == LEASTLATENCY {
+		return "least_latency"
+	}
+	return "round_robin"
+}
+
+// Время, в течение которого недоступная реплика пропускается, по умолчанию
+// ======================================================================================
+// Default time during which an unavailable replica is skipped
+const DefaultReplicaCooldown = 5 * time.Second
+
+// Обработчик, который умеет проверять соединение, StdDbHandler реализует этот интерфейс
+// ======================================================================================
+// Handler that is able to check the connection, StdDbHandler implements this interface
+type Pinger interface {
+	PingContext(context context.Context) error
+}
+
+type primaryKey struct{}
+
+// Возвращает контекст, запросы с которым RoutingDbHandler выполняет на основной базе, например чтобы прочитать только что записанные данные
+// ======================================================================================
+// Returns the context with which RoutingDbHandler executes queries on the primary database, for example to read the data that has just been written
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Требует ли контекст основную базу / Reports whether the context requires the primary database
+func IsPrimary(context context.Context) bool {
+	primary, _ := context.Value(primaryKey{}).(bool)
+	return primary
+}
+
+//region Handlers Realization
+
+func (stdh *StdDbHandler) PingContext(context context.Context) error {
+	stdh.dbMutex.RLock()
+	defer stdh.dbMutex.RUnlock()
+
+	return stdh.db.PingContext(context)
+}
+
+//endregion
+
+//region Routing Handler
+
+// Обработчик с разделением чтения и записи: SelectContext и QueryContext чтения выполняются на одной из реплик,
+// InsertContext, ExecContext, запросы записи с RETURNING и транзакции - на основной базе
+// Запрос без операции DB (Exec, SelectQuery, Iterate) идет на реплику, только если это SELECT без FOR UPDATE/FOR SHARE
+// Реплика, вернувшая ошибку соединения, пропускается DefaultReplicaCooldown (см. ChangeCooldown), а запрос повторяется на основной базе
+// Если доступных реплик нет, то чтение выполняется на основной базе
+// Подключается через DB.ChangeHandler
+// ======================================================================================
+// Handler with read/write splitting: SelectContext and reading QueryContext are executed on one of the replicas,
+// InsertContext, ExecContext, writing queries with RETURNING and transactions are executed on the primary database
+// A query without a DB operation (Exec, SelectQuery, Iterate) goes to a replica only if it is a SELECT without FOR UPDATE/FOR SHARE
+// A replica that returned a connection error is skipped for DefaultReplicaCooldown (see ChangeCooldown) and the query is repeated on the primary database
+// If there are no available replicas reading is executed on the primary database
+// It is plugged in via DB.ChangeHandler
+type RoutingDbHandler struct {
+	primary  DbHandler
+	replicas []*replica
+	counter  atomic.Uint64
+	strategy atomic.Int32
+	cooldown atomic.Int64
+}
+
+// handler - обработчик реплики ;
+// latency - скользящее среднее длительности запросов в наносекундах, 0 - еще не измерено ;
+// downUntil - время в наносекундах Unix, до которого реплика пропускается ;
+// ======================================================================================
+// handler - handler of the replica ;
+// latency - moving average of the query duration in nanoseconds, 0 means not measured yet ;
+// downUntil - time in Unix nanoseconds until which the replica is skipped ;
+type replica struct {
+	handler   DbHandler
+	latency   atomic.Int64
+	downUntil atomic.Int64
+}
+
+func GetRoutingDbHandler(primary *sql.DB, replicas ...*sql.DB) *RoutingDbHandler {
+	handlers := make([]DbHandler, len(replicas))
+	for i, db := range replicas {
+		handlers[i] = GetStdDbHandler(db)
+	}
+	return GetRoutingDbHandlerFrom(GetStdDbHandler(primary), handlers...)
+}
+
+// То же что и GetRoutingDbHandler, но основная база и реплики задаются обработчиками
+// ======================================================================================
+// Same as GetRoutingDbHandler, but the primary database and the replicas are specified by handlers
+func GetRoutingDbHandlerFrom(primary DbHandler, replicas ...DbHandler) *RoutingDbHandler {
+	handler := &RoutingDbHandler{
+		primary: primary,
+	}
+	for _, r := range replicas {
+		handler.replicas = append(handler.replicas, &replica{handler: r})
+	}
+	handler.cooldown.Store(int64(DefaultReplicaCooldown))
+	return handler
+}
+
+func (h *RoutingDbHandler) ChangeStrategy(strategy RoutingStrategy) {
+	h.strategy.Store(int32(strategy))
+}
+
+func (h *RoutingDbHandler) Strategy() RoutingStrategy {
+	return RoutingStrategy(h.strategy.Load())
+}
+
+func (h *RoutingDbHandler) ChangeCooldown(cooldown time.Duration) {
+	h.cooldown.Store(int64(cooldown))
+}
+
+// Заменяет сканер основной базы и реплик, если их обработчики позволяют это сделать
+// ======================================================================================
+// Replaces the scanner of the primary database and the replicas if their handlers allow it
+func (h *RoutingDbHandler) ChangeScanner(sc sqlreflect.Scanner) {
+	type scannerChanger interface {
+		ChangeScanner(sc sqlreflect.Scanner)
+	}
+
+	if changer, ok := h.primary.(scannerChanger); ok {
+		changer.ChangeScanner(sc)
+	}
+	for _, r := range h.replicas {
+		if changer, ok := r.handler.(scannerChanger); ok {
+			changer.ChangeScanner(sc)
+		}
+	}
+}
+
+// Проверяет соединение со всеми репликами, доступные реплики возвращаются в работу, недоступные пропускаются
+// Возвращает ошибки недоступных реплик
+// ======================================================================================
+// Checks the connection to all replicas, available replicas are returned to work, unavailable ones are skipped
+// Returns the errors of unavailable replicas
+func (h *RoutingDbHandler) CheckReplicas(context context.Context) error {
+	var errs []error
+	for _, r := range h.replicas {
+		pinger, ok := r.handler.(Pinger)
+		if !ok {
+			continue
+		}
+		if err := pinger.PingContext(context); err != nil {
+			h.markDown(r)
+			errs = append(errs, err)
+			continue
+		}
+		r.downUntil.Store(0)
+	}
+	return errors.Join(errs...)
+}
+
+// Количество реплик, которые сейчас не пропускаются / Number of replicas that are not skipped now
+func (h *RoutingDbHandler) HealthyReplicas() int {
+	now := time.Now().UnixNano()
+	count := 0
+	for _, r := range h.replicas {
+		if r.downUntil.Load() <= now {
+			count++
+		}
+	}
+	return count
+}
+
+func (h *RoutingDbHandler) markDown(r *replica) {
+	r.downUntil.Store(time.Now().Add(time.Duration(h.cooldown.Load())).UnixNano())
+}
+
+// Выбирает реплику для чтения, nil - чтение выполняется на основной базе
+// Запросы с операцией записи в контексте (INSERT ... RETURNING через QueryContext) всегда идут на основную базу
+func (h *RoutingDbHandler) pick(context context.Context, query string) *replica {
+	if len(h.replicas) == 0 || IsPrimary(context) {
+		return nil
+	}
+	// без операции DB (Exec, SelectQuery, Iterate) запрос классифицируется по тексту
+	if op, ok := OperationFromContext(context); (ok && op != OPSELECT) || (!ok && !readQuery(query)) {
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+
+	if h.Strategy() == LEASTLATENCY {
+		var best *replica
+		for _, r := range h.replicas {
+			if r.downUntil.Load() > now {
+				continue
+			}
+			if best == nil || r.latency.Load() < best.latency.Load() {
+				best = r
+			}
+		}
+		return best
+	}
+
+	start := h.counter.Add(1)
+	for i := range uint64(len(h.replicas)) {
+		r := h.replicas[(start+i)%uint64(len(h.replicas))]
+		if r.downUntil.Load() <= now {
+			return r
+		}
+	}
+	return nil
+}
+
+// Является ли запрос чтением: начинается с SELECT и не блокирует строки (FOR UPDATE, FOR SHARE)
+// WITH не считается чтением, так как CTE может изменять данные
+func readQuery(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	if len(query) < len("SELECT") || !strings.EqualFold(query[:len("SELECT")], "SELECT") {
+		return false
+	}
+
+	upper := strings.ToUpper(query)
+	return !strings.Contains(upper, "FOR UPDATE") && !strings.Contains(upper, "FOR SHARE") && !strings.Contains(upper, "FOR NO KEY UPDATE")
+}
+
+// Выполняет чтение на реплике, при ошибке соединения помечает реплику недоступной и повторяет чтение на основной базе
+func (h *RoutingDbHandler) read(context context.Context, query string, call func(handler DbHandler) error) error {
+	r := h.pick(context, query)
+	if r == nil {
+		return call(h.primary)
+	}
+
+	start := time.Now()
+	err := call(r.handler)
+
+	if err == nil {
+		// экспоненциальное скользящее среднее с весом 1/5 для нового значения
+		latency := time.Since(start).Nanoseconds()
+		if old := r.latency.Load(); old > 0 {
+			latency = (old*4 + latency) / 5
+		}
+		r.latency.Store(max(latency, 1))
+		return nil
+	}
+
+	if context.Err() != nil || !connectionError(err) {
+		return err
+	}
+
+	h.markDown(r)
+
+	return call(h.primary)
+}
+
+// Ошибки, после которых реплика считается недоступной / Errors after which the replica is considered unavailable
+func connectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr)
+}
+
+func (h *RoutingDbHandler) SelectContext(context context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	reset := destReset(dest)
+
+	return h.read(context, query, func(handler DbHandler) error {
+		reset()
+		return handler.SelectContext(context, dest, query, queryConfig, args...)
+	})
+}
+
+func (h *RoutingDbHandler) QueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (rows ResultRows, err error) {
+	err = h.read(context, query, func(handler DbHandler) error {
+		rows, err = queryNext(context, handler, query, queryConfig, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (h *RoutingDbHandler) InsertContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.primary.InsertContext(context, query, queryConfig, args...)
+}
+
+func (h *RoutingDbHandler) ExecContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.primary.ExecContext(context, query, queryConfig, args...)
+}
+
+func (h *RoutingDbHandler) ExecResultContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (sql.Result, error) {
+	return execResultNext(context, h.primary, query, queryConfig, args...)
+}
+
+// Транзакции всегда выполняются на основной базе / Transactions are always executed on the primary database
+func (h *RoutingDbHandler) BeginTxHandler(context context.Context, opts *sql.TxOptions) (TxHandler, error) {
+	beginner, ok := h.primary.(TxBeginner)
+	if !ok {
+		return nil, errors.New("handler does not support transactions")
+	}
+	return beginner.BeginTxHandler(context, opts)
+}
+
+// Сканер основной базы / Scanner of the primary database
+func (h *RoutingDbHandler) Scanner() sqlreflect.Scanner {
+	return handlerScanner(h.primary)
+}
+
+func (h *RoutingDbHandler) PingContext(context context.Context) error {
+	if pinger, ok := h.primary.(Pinger); ok {
+		return pinger.PingContext(context)
+	}
+	return nil
+}
+
+func (h *RoutingDbHandler) Select(dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return h.SelectContext(context.Background(), dest, query, queryConfig, args...)
+}
+
+func (h *RoutingDbHandler) Insert(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.InsertContext(context.Background(), query, queryConfig, args...)
+}
+
+func (h *RoutingDbHandler) Exec(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return h.ExecContext(context.Background(), query, queryConfig, args...)
+}
+
+//endregion
@@ -0,0 +1,161 @@
+package gosql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type pingHandler struct {
+	fakeHandler
+	pingErr error
+}
+
+func (p *pingHandler) PingContext(_ context.Context) error {
+	return p.pingErr
+}
+
+func TestRouting(t *testing.T) {
+	primary := &fakeBeginner{}
+	first := &pingHandler{}
+	second := &pingHandler{}
+
+	routing := GetRoutingDbHandlerFrom(primary, first, second)
+
+	db := GetDb(nil, "main")
+	db.ChangeHandler(routing)
+
+	qc := sqlstrings.QueryConfig{TableName: "Roles", Item: Roles{}, Dialect: sqlstrings.POSTGRES}
+
+	var roles []Roles
+	for range 4 {
+		if err := db.Select(qc, &roles); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if len(first.queries) != 2 || len(second.queries) != 2 || len(primary.queries) != 0 {
+		t.Errorf("reads must be balanced between replicas: %d %d %d", len(first.queries), len(second.queries), len(primary.queries))
+	}
+
+	// запись и чтение с WithPrimary идут на основную базу
+	if _, err := db.Insert(qc.ChangeItem(Roles{Title: "admin"})); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := db.Exec("DELETE FROM Roles"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := db.SelectContext(WithPrimary(context.Background()), qc, &roles); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(primary.queries) != 3 || len(first.queries) != 2 || len(second.queries) != 2 {
+		t.Errorf("writes must go to the primary: %d %d %d", len(primary.queries), len(first.queries), len(second.queries))
+	}
+
+	// INSERT ... RETURNING через QueryContext тоже идет на основную базу
+	primary.columns = []string{"Id"}
+	primary.rows = [][]any{{7}}
+	if _, err := db.Insert(qc.ChangeItem(Roles{Title: "user"}).ChangeReturning("Id")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(primary.queries) != 4 {
+		t.Errorf("insert with RETURNING must go to the primary: %d", len(primary.queries))
+	}
+
+	// сырые запросы без операции DB классифицируются по тексту
+	primary.rows, primary.columns = nil, nil
+	for _, query := range []string{
+		`UPDATE "Roles" SET "Title" = $1 RETURNING "Id", "Title"`,
+		`SELECT "Id", "Title" FROM "Roles" FOR UPDATE`,
+		`WITH deleted AS (DELETE FROM "Roles" RETURNING *) SELECT * FROM deleted`,
+	} {
+		if err := db.SelectQuery(query, qc, &roles); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if len(primary.queries) != 7 || len(first.queries)+len(second.queries) != 4 {
+		t.Errorf("raw writes must go to the primary: %d %d %d", len(primary.queries), len(first.queries), len(second.queries))
+	}
+
+	if err := db.SelectQuery(`  select "Id" FROM "Roles"`, qc, &roles); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(primary.queries) != 7 || len(first.queries)+len(second.queries) != 5 {
+		t.Errorf("raw SELECT must go to a replica: %d %d %d", len(primary.queries), len(first.queries), len(second.queries))
+	}
+
+	// транзакции начинаются на основной базе
+	if err := db.WithTx(context.Background(), func(tx *Tx) error { return nil }); err != nil || !primary.tx.committed {
+		t.Errorf("transaction must be committed on the primary: %v", err)
+	}
+}
+
+func TestRoutingFallback(t *testing.T) {
+	primary := &fakeBeginner{}
+	first := &pingHandler{fakeHandler: fakeHandler{err: driver.ErrBadConn}}
+	second := &pingHandler{fakeHandler: fakeHandler{err: driver.ErrBadConn}}
+
+	routing := GetRoutingDbHandlerFrom(primary, first, second)
+	routing.ChangeCooldown(time.Hour)
+
+	db := GetDb(nil, "main")
+	db.ChangeHandler(routing)
+
+	qc := sqlstrings.QueryConfig{TableName: "Roles", Item: Roles{}}
+
+	var roles []Roles
+	for range 2 {
+		if err := db.Select(qc, &roles); err != nil {
+			t.Fatalf("read must fall back to the primary: %s", err)
+		}
+	}
+
+	if routing.HealthyReplicas() != 0 || len(primary.queries) != 2 {
+		t.Errorf("replicas must be marked unhealthy: %d %d", routing.HealthyReplicas(), len(primary.queries))
+	}
+
+	// пока реплики пропускаются, чтение идет сразу на основную базу
+	if err := db.Select(qc, &roles); err != nil || len(first.queries)+len(second.queries) != 2 {
+		t.Errorf("unhealthy replicas must be skipped: %v", err)
+	}
+
+	// ошибки запроса, не связанные с соединением, не переключают на основную базу
+	first.err, second.err = nil, nil
+	second.pingErr = errors.New("down")
+	if err := routing.CheckReplicas(context.Background()); err == nil || routing.HealthyReplicas() != 1 {
+		t.Errorf("check must return the replica to work: %v %d", err, routing.HealthyReplicas())
+	}
+
+	first.err = errors.New("syntax error")
+	if err := db.Select(qc, &roles); err == nil || routing.HealthyReplicas() != 1 {
+		t.Errorf("query error must be returned as is: %v", err)
+	}
+}
+
+func TestRoutingLeastLatency(t *testing.T) {
+	primary := &fakeBeginner{}
+	fast := &pingHandler{}
+	slow := &pingHandler{}
+
+	routing := GetRoutingDbHandlerFrom(primary, slow, fast)
+	routing.ChangeStrategy(LEASTLATENCY)
+
+	routing.replicas[0].latency.Store(int64(time.Second))
+	routing.replicas[1].latency.Store(int64(time.Millisecond))
+
+	var roles []Roles
+	for range 3 {
+		if err := routing.Select(&roles, "SELECT * FROM Roles", sqlstrings.QueryConfig{Item: Roles{}}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	if len(fast.queries) != 3 || len(slow.queries) != 0 {
+		t.Errorf("the fastest replica must be chosen: %d %d", len(fast.queries), len(slow.queries))
+	}
+}
@@ -0,0 +1,190 @@
+package sqlreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Строки, которые знают имена своих столбцов, *sql.Rows реализует этот интерфейс
+// Если rows реализует этот интерфейс, то столбцы сопоставляются с полями по тегам, иначе по порядку полей структуры
+// ======================================================================================
+// Rows that know the names of their columns, *sql.Rows implements this interface
+// If rows implements this interface columns are matched to fields by tags, otherwise by the order of the struct fields
+type ColumnsRowScanner interface {
+	RowScanner
+	Columns() ([]string, error)
+}
+
+// Политика для столбцов результата, которым не соответствует ни одно поле структуры
+// ======================================================================================
+// Policy for result columns that have no matching struct field
+type UnknownColumns int
+
+const (
+	// значение читается и отбрасывается / the value is read and discarded
+	IGNOREUNKNOWN UnknownColumns = iota
+	// значение передается в StdScanner.Sink / the value is passed to StdScanner.Sink
+	SINKUNKNOWN
+	// сканирование завершается с ошибкой ErrUnknownColumn / scanning fails with ErrUnknownColumn
+	ERRORUNKNOWN
+	// как IGNOREUNKNOWN, но если ни один столбец не совпал и их количество равно количеству полей, то сканирование идет по порядку полей (старое поведение)
+	// as IGNOREUNKNOWN, but if no column matches and their count equals the number of fields, scanning goes by the order of the fields (the old behaviour)
+	POSITIONALUNKNOWN
+)
+
+// В результате есть столбец без соответствующего поля / The result has a column without a matching field
+var ErrUnknownColumn = errors.New("unknown column")
+
+// Ни один столбец результата не соответствует полю, сканирование по порядку включается только политикой POSITIONALUNKNOWN
+// ======================================================================================
+// No result column matches a field, positional scanning is enabled only by the POSITIONALUNKNOWN policy
+var ErrNoMatchingColumns = errors.New("no result column matches a field")
+
+// Сканер, который сопоставляет столбцы с полями один раз на весь результат, StdScanner реализует этот интерфейс
+// Возвращаемая функция сканирует текущую строку rows в dest - указатель на структуру типа itemType
+// ======================================================================================
+// Scanner that matches columns to fields once for the whole result, StdScanner implements this interface
+// The returned function scans the current row of rows into dest - a pointer to the struct of the itemType type
+type RowsPreparer interface {
+	PrepareRows(itemType reflect.Type, rows RowScanner, queryConfig sqlstrings.QueryConfig) (func(dest any) error, error)
+}
+
+func (sc *StdScanner) PrepareRows(itemType reflect.Type, rows RowScanner, queryConfig sqlstrings.QueryConfig) (func(dest any) error, error) {
+	typeMap, err := sc.Mapper.Map(itemType, queryConfig.TagName)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, columns, err := sc.columnFields(rows, typeMap, queryConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(dest any) error {
+		item := reflect.ValueOf(dest)
+		if item.Kind() != reflect.Pointer || item.Elem().Type() != typeMap.NonRefType {
+			return fmt.Errorf("%w: dest must be a pointer to %s", ErrInvalidDest, typeMap.NonRefType)
+		}
+		return sc.scanRow(item, rows, typeMap, queryConfig, fields, columns)
+	}, nil
+}
+
+// Возвращает для каждого столбца результата индекс поля в typeMap.Fields или -1 и имена столбцов, nil если сопоставлять нужно по порядку
+// Столбцы из ExcludedTags считаются неизвестными, одинаковые теги занимают поля по порядку, регистр имен учитывается только если есть точное совпадение
+// ======================================================================================
+// Returns the index of the field in typeMap.Fields or -1 for every result column and the column names, nil if matching must be done by order
+// Columns from ExcludedTags are considered unknown, equal tags take fields in order, the case of names matters only if there is an exact match
+func (sc *StdScanner) columnFields(rows RowScanner, typeMap *TypeMap, queryConfig sqlstrings.QueryConfig) ([]int, []string, error) {
+	columnsRows, ok := rows.(ColumnsRowScanner)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	columns, err := columnsRows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// драйвер не сообщил имена столбцов
+	if len(columns) == 0 {
+		return nil, nil, nil
+	}
+
+	used := make([]bool, len(typeMap.Fields))
+	fields := make([]int, len(columns))
+	matched := false
+
+	// сначала ищется точное совпадение, затем без учета регистра, т.к. POSTGRES приводит имена без кавычек к нижнему регистру
+	for idx, column := range columns {
+		fields[idx] = -1
+
+		if slices.ContainsFunc(queryConfig.ExcludedTags, func(tag string) bool { return strings.EqualFold(tag, column) }) {
+			continue
+		}
+
+		for _, equal := range []func(a, b string) bool{func(a, b string) bool { return a == b }, strings.EqualFold} {
+			for fieldIdx, fieldInfo := range typeMap.Fields {
+				if !used[fieldIdx] && equal(fieldInfo.FTag, column) {
+					fields[idx] = fieldIdx
+					used[fieldIdx] = true
+					matched = true
+					break
+				}
+			}
+			if fields[idx] >= 0 {
+				break
+			}
+		}
+	}
+
+	// ни один столбец не совпал с полем: сканирование по порядку полей только при явной политике POSITIONALUNKNOWN
+	if !matched {
+		if sc.UnknownColumns == POSITIONALUNKNOWN {
+			positional := 0
+			for _, fieldInfo := range typeMap.Fields {
+				if !slices.Contains(queryConfig.ExcludedTags, fieldInfo.FTag) {
+					positional++
+				}
+			}
+			if positional == len(columns) {
+				return nil, nil, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("%w: %v for %s", ErrNoMatchingColumns, columns, typeMap.NonRefType)
+	}
+
+	if sc.UnknownColumns == ERRORUNKNOWN {
+		if idx := slices.Index(fields, -1); idx >= 0 {
+			return nil, nil, fmt.Errorf("%w %q for %s", ErrUnknownColumn, columns[idx], typeMap.NonRefType)
+		}
+	}
+
+	return fields, columns, nil
+}
+
+// Индекс поля со столбцом column: точное совпадение, иначе без учета регистра, -1 если поля нет
+func columnField(typeMap *TypeMap, column string) int {
+	if idx := slices.IndexFunc(typeMap.Fields, func(f *FieldInfo) bool { return f.FTag == column }); idx >= 0 {
+		return idx
+	}
+	return slices.IndexFunc(typeMap.Fields, func(f *FieldInfo) bool { return strings.EqualFold(f.FTag, column) })
+}
+
+func (sc *StdScanner) scanRow(item reflect.Value, rows RowScanner, typeMap *TypeMap, queryConfig sqlstrings.QueryConfig, fields []int, columns []string) error {
+	if fields == nil {
+		return rows.Scan(GetFieldsPointersOfItem(item, typeMap, queryConfig.ExcludedTags)...)
+	}
+
+	v := item.Elem()
+	pointers := make([]any, len(fields))
+
+	for idx, fieldIdx := range fields {
+		if fieldIdx >= 0 {
+			pointers[idx] = fieldPointer(v, typeMap.Fields[fieldIdx])
+		}
+		if pointers[idx] == nil {
+			pointers[idx] = new(any)
+		}
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return err
+	}
+
+	if sc.UnknownColumns != SINKUNKNOWN || sc.Sink == nil {
+		return nil
+	}
+
+	for idx, fieldIdx := range fields {
+		if fieldIdx < 0 {
+			sc.Sink(columns[idx], *pointers[idx].(*any))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package sqlreflect
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type namedRows struct {
+	columns []string
+	values  [][]any
+	idx     int
+}
+
+func (r *namedRows) Columns() ([]string, error) {
+	return r.columns, nil
+}
+
+func (r *namedRows) Next() bool {
+	r.idx++
+	return r.idx < len(r.values)
+}
+
+func (r *namedRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[r.idx][i]))
+	}
+	return nil
+}
+
+type columnsUser struct {
+	Id       int     `db:"Id"`
+	Name     string  `db:"Name"`
+	Nickname *string `db:"Nickname"`
+}
+
+func TestScanByColumnNames(t *testing.T) {
+	qc := sqlstrings.QueryConfig{TagName: "db"}
+
+	rows := func() *namedRows {
+		return &namedRows{
+			columns: []string{"Nickname", "Extra", "Id"},
+			values:  [][]any{{"bobby", 42, 1}, {"alice", 43, 2}},
+			idx:     -1,
+		}
+	}
+
+	sc := &StdScanner{Mapper: GetMapper()}
+
+	var users []columnsUser
+	if err := sc.Scan(&users, rows(), qc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(users) != 2 || users[1].Id != 2 || *users[1].Nickname != "alice" || users[1].Name != "" {
+		t.Errorf("scan by names failed: %#v", users)
+	}
+
+	sunk := map[string]any{}
+	sc.UnknownColumns = SINKUNKNOWN
+	sc.Sink = func(column string, value any) { sunk[column] = value }
+
+	var user columnsUser
+	r := rows()
+	r.Next()
+	if err := sc.ScanRow(&user, r, qc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if user.Id != 1 || sunk["Extra"] != 42 || len(sunk) != 1 {
+		t.Errorf("sink failed: %#v %#v", user, sunk)
+	}
+
+	sc.UnknownColumns = ERRORUNKNOWN
+	if err := sc.Scan(&users, rows(), qc); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+
+	if err := sc.Scan(&users, rows(), qc.ChangeExcludedTags("Nickname")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("excluded column must be unknown, got %v", err)
+	}
+}
+
+func TestScanCaseInsensitiveColumns(t *testing.T) {
+	qc := sqlstrings.QueryConfig{TagName: "db"}
+	sc := &StdScanner{Mapper: GetMapper(), UnknownColumns: ERRORUNKNOWN}
+
+	// POSTGRES возвращает имена без кавычек в нижнем регистре
+	rows := &namedRows{
+		columns: []string{"id", "name", "nickname"},
+		values:  [][]any{{1, "bob", "bobby"}},
+		idx:     -1,
+	}
+
+	var users []columnsUser
+	if err := sc.Scan(&users, rows, qc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(users) != 1 || users[0].Id != 1 || users[0].Name != "bob" || *users[0].Nickname != "bobby" {
+		t.Errorf("scan by lower-cased names failed: %#v", users)
+	}
+
+	// ни один столбец не совпал, количество совпадает: по умолчанию ошибка, а не сканирование по порядку
+	positionalRows := func() *namedRows {
+		return &namedRows{
+			columns: []string{"?column?", "upper", "coalesce"},
+			values:  [][]any{{2, "alice", "al"}},
+			idx:     -1,
+		}
+	}
+
+	for _, policy := range []UnknownColumns{IGNOREUNKNOWN, SINKUNKNOWN, ERRORUNKNOWN} {
+		if err := (&StdScanner{Mapper: GetMapper(), UnknownColumns: policy}).Scan(&users, positionalRows(), qc); !errors.Is(err, ErrNoMatchingColumns) {
+			t.Errorf("policy %d: expected ErrNoMatchingColumns, got %v", policy, err)
+		}
+	}
+
+	// сканирование по порядку включается явно
+	positional := &StdScanner{Mapper: GetMapper(), UnknownColumns: POSITIONALUNKNOWN}
+	if err := positional.Scan(&users, positionalRows(), qc); err != nil || len(users) != 1 || users[0].Id != 2 || users[0].Name != "alice" {
+		t.Errorf("positional fallback failed: %#v %v", users, err)
+	}
+
+	// ни один столбец не совпал, количество другое: ошибка вместо пустых структур
+	rows = &namedRows{
+		columns: []string{"count"},
+		values:  [][]any{{3}},
+		idx:     -1,
+	}
+
+	if err := (&StdScanner{Mapper: GetMapper()}).Scan(&users, rows, qc); !errors.Is(err, ErrNoMatchingColumns) {
+		t.Errorf("expected ErrNoMatchingColumns, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package sqlreflect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Тег поля-связи: rel:"has_many,fk=UserId", rel:"belongs_to", rel:"many2many,through=UserRoles"
+// ======================================================================================
+// Tag of a relation field: rel:"has_many,fk=UserId", rel:"belongs_to", rel:"many2many,through=UserRoles"
+const RelTagName = "rel"
+
+type RelationKind int
+
+const (
+	HASMANY RelationKind = iota
+	BELONGSTO
+	MANY2MANY
+)
+
+// Name - имя поля-связи ;
+// Kind - вид связи ;
+// Index - путь к полю ;
+// Ftype - тип поля, срез для HASMANY и MANY2MANY, структура или указатель для BELONGSTO ;
+// Elem - тип связанной структуры ;
+// Table - таблица связанной структуры, опция table, по умолчанию имя типа ;
+// FK - HASMANY: столбец связанной таблицы (по умолчанию <Владелец>Id), BELONGSTO: столбец владельца (по умолчанию <Поле>Id), MANY2MANY: столбец Through ссылающийся на владельца (по умолчанию <Владелец>Id) ;
+// PK - HASMANY и MANY2MANY: ключ владельца, BELONGSTO: ключ связанной таблицы, по умолчанию Id ;
+// Through - MANY2MANY: промежуточная таблица ;
+// Ref - MANY2MANY: столбец Through ссылающийся на связанную таблицу, по умолчанию <Связанный тип>Id ;
+// RefPK - MANY2MANY: ключ связанной таблицы, по умолчанию Id ;
+// ======================================================================================
+// Name - name of the relation field ;
+// Kind - kind of the relation ;
+// Index - path to the field ;
+// Ftype - type of the field, a slice for HASMANY and MANY2MANY, a struct or a pointer for BELONGSTO ;
+// Elem - type of the related struct ;
+// Table - table of the related struct, the table option, the type name by default ;
+// FK - HASMANY: column of the related table (<Owner>Id by default), BELONGSTO: column of the owner (<Field>Id by default), MANY2MANY: Through column referencing the owner (<Owner>Id by default) ;
+// PK - HASMANY and MANY2MANY: key of the owner, BELONGSTO: key of the related table, Id by default ;
+// Through - MANY2MANY: link table ;
+// Ref - MANY2MANY: Through column referencing the related table, <Related type>Id by default ;
+// RefPK - MANY2MANY: key of the related table, Id by default ;
+type RelationInfo struct {
+	Name    string
+	Kind    RelationKind
+	Index   []int
+	Ftype   reflect.Type
+	Elem    reflect.Type
+	Table   string
+	FK      string
+	PK      string
+	Through string
+	Ref     string
+	RefPK   string
+}
+
+// Ошибка в теге rel / Error in the rel tag
+var ErrInvalidRelation = errors.New("invalid relation")
+
+// Возвращает связи верхнего уровня структуры t
+// ======================================================================================
+// Returns the top level relations of the t struct
+func mapRelations(t reflect.Type) ([]*RelationInfo, error) {
+	var relations []*RelationInfo
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(RelTagName)
+		if !ok {
+			continue
+		}
+
+		relation, err := parseRelation(t, field, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		relations = append(relations, relation)
+	}
+
+	return relations, nil
+}
+
+func parseRelation(owner reflect.Type, field reflect.StructField, tag string) (*RelationInfo, error) {
+	parsed := sqlstrings.ParseTag(tag)
+
+	relation := &RelationInfo{
+		Name:  field.Name,
+		Index: field.Index,
+		Ftype: field.Type,
+		PK:    "Id",
+		RefPK: "Id",
+	}
+
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %s.%s: %s", ErrInvalidRelation, owner.Name(), field.Name, reason)
+	}
+
+	if !field.IsExported() {
+		return nil, invalid("field must be exported")
+	}
+
+	elem := field.Type
+	switch parsed.Name {
+	case "has_many":
+		relation.Kind = HASMANY
+	case "belongs_to":
+		relation.Kind = BELONGSTO
+	case "many2many":
+		relation.Kind = MANY2MANY
+	default:
+		return nil, invalid("unknown kind " + parsed.Name)
+	}
+
+	if relation.Kind != BELONGSTO {
+		if elem.Kind() != reflect.Slice {
+			return nil, invalid("field must be a slice")
+		}
+		elem = elem.Elem()
+	}
+
+	relation.Elem = ConversionTypeToNonRefType(elem)
+	if relation.Elem.Kind() != reflect.Struct || (elem.Kind() == reflect.Pointer && elem.Elem() != relation.Elem) {
+		return nil, invalid("related type must be a struct or a pointer to the struct")
+	}
+
+	relation.Table = relation.Elem.Name()
+
+	switch relation.Kind {
+	case HASMANY, MANY2MANY:
+		relation.FK = owner.Name() + "Id"
+	case BELONGSTO:
+		relation.FK = field.Name + "Id"
+	}
+	relation.Ref = relation.Elem.Name() + "Id"
+
+	for _, option := range parsed.Options {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "fk":
+			relation.FK = value
+		case "pk":
+			relation.PK = value
+		case "table":
+			relation.Table = value
+		case "through":
+			relation.Through = value
+		case "ref":
+			relation.Ref = value
+		case "refpk":
+			relation.RefPK = value
+		default:
+			return nil, invalid("unknown option " + key)
+		}
+	}
+
+	if relation.Kind == MANY2MANY && len(relation.Through) == 0 {
+		return nil, invalid("many2many requires the through option")
+	}
+
+	return relation, nil
+}
@@ -3,24 +3,50 @@ package sqlreflect
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
 )
 
+var (
+	// dest не является указателем на срез или структуру / dest is not a pointer to slice or struct
+	ErrInvalidDest = errors.New("invalid dest")
+	// тип не является структурой или указателем на структуру / the type is neither a struct nor a pointer to the struct
+	ErrUnmappableType = errors.New("type need to be either struct or pointer to the struct")
+)
+
 type Scanner interface {
 	Scan(dest any, rows RowScanner, queryConfig sqlstrings.QueryConfig) error
 }
 
+// Сканер, который умеет сканировать одну текущую строку, нужен для построчной итерации
+// ======================================================================================
+// Scanner that is able to scan a single current row, it is needed for row by row iteration
+type RowByRowScanner interface {
+	Scanner
+	ScanRow(dest any, rows RowScanner, queryConfig sqlstrings.QueryConfig) error
+}
+
 type RowScanner interface {
 	Scan(dest ...any) error
 	Next() bool
 }
 
+// Mapper - маппер типов ;
+// UnknownColumns - что делать со столбцами результата, для которых нет поля в структуре, используется если rows реализует ColumnsRowScanner ;
+// Sink - получает значения неизвестных столбцов при UnknownColumns = SINKUNKNOWN ;
+// ======================================================================================
+// Mapper - mapper of types ;
+// UnknownColumns - what to do with result columns that have no field in the struct, it is used if rows implements ColumnsRowScanner ;
+// Sink - receives values of unknown columns when UnknownColumns = SINKUNKNOWN ;
 type StdScanner struct {
-	Mapper *Mapper
+	Mapper         *Mapper
+	UnknownColumns UnknownColumns
+	Sink           func(column string, value any)
 }
 
 type Mapper struct {
@@ -29,16 +55,38 @@ type Mapper struct {
 	cacheLock sync.RWMutex
 }
 
+// PK - поля первичного ключа (опция тега pk) в порядке объявления / fields of the primary key (the pk tag option) in declaration order
 type TypeMap struct {
 	NonRefType reflect.Type
 	TagName    string
 	Fields     []*FieldInfo
+	Relations  []*RelationInfo
+	PK         []*FieldInfo
 }
 
+// Name - имя поля ;
+// Ftype - тип поля ;
+// FTag - имя столбца, для полей вложенных структур с префиксом ;
+// Index - путь к полю для reflect.Value.FieldByIndex, поля встроенных структур имеют путь длиннее 1 ;
+// PK, Auto, ReadOnly, InsertOnly, UpdateOnly, OmitEmpty, Secret - опции тега pk, auto, readonly, insert, update, omitempty, secret (см. sqlstrings.PKOption) ;
+// ======================================================================================
+// Name - field name ;
+// Ftype - field type ;
+// FTag - column name, with the prefix for fields of nested structs ;
+// Index - path to the field for reflect.Value.FieldByIndex, fields of embedded structs have a path longer than 1 ;
+// PK, Auto, ReadOnly, InsertOnly, UpdateOnly, OmitEmpty, Secret - the pk, auto, readonly, insert, update, omitempty, secret tag options (see sqlstrings.PKOption) ;
 type FieldInfo struct {
-	Name  string
-	Ftype reflect.Type
-	FTag  string
+	Name       string
+	Ftype      reflect.Type
+	FTag       string
+	Index      []int
+	PK         bool
+	Auto       bool
+	ReadOnly   bool
+	InsertOnly bool
+	UpdateOnly bool
+	OmitEmpty  bool
+	Secret     bool
 }
 
 // map the item, panics if type of item isn`t struct or pointer to the struct
@@ -48,23 +96,26 @@ func MapFunc(item reflect.Type, tagName string) (*TypeMap, error) {
 
 	ogItemType := item
 
-	myerr := errors.New("type need to be either struct or pointer to the struct")
-
 	if kind := ogItemType.Kind(); kind != reflect.Pointer && kind != reflect.Struct {
-		return nil, myerr
+		return nil, fmt.Errorf("%w, got %s", ErrUnmappableType, item)
 	}
 
 	nonRefItemType := ConversionTypeToNonRefType(item)
 
 	if kind := nonRefItemType.Kind(); kind != reflect.Struct {
-		return nil, myerr
+		return nil, fmt.Errorf("%w, got %s", ErrUnmappableType, item)
 	}
 
 	fields := []*FieldInfo{}
 
-	for i := range nonRefItemType.NumField() {
-		field := nonRefItemType.Field(i)
-		columnName := field.Tag.Get(tagName)
+	if len(tagName) == 0 {
+		tagName = sqlstrings.StdTagName
+	}
+
+	// встроенные и вложенные структуры разворачиваются так же как и в генераторах sqlstrings
+	for _, structField := range sqlstrings.StructFields(nonRefItemType, tagName) {
+		field := structField.Field
+		columnName := structField.Column
 		ogType := field.Type
 		nonRefType := ConversionTypeToNonRefType(ogType)
 		clName := len(columnName) > 0
@@ -79,15 +130,40 @@ func MapFunc(item reflect.Type, tagName string) (*TypeMap, error) {
 			fieldInfo.Name = field.Name
 
 			fieldInfo.FTag = columnName
+
+			fieldInfo.Index = structField.Index
+
+			tag := structField.Tag
+			fieldInfo.PK = tag.HasOption(sqlstrings.PKOption)
+			fieldInfo.Auto = tag.HasOption(sqlstrings.AutoOption)
+			fieldInfo.ReadOnly = tag.HasOption(sqlstrings.ReadOnlyOption)
+			fieldInfo.InsertOnly = tag.HasOption(sqlstrings.InsertOption)
+			fieldInfo.UpdateOnly = tag.HasOption(sqlstrings.UpdateOption)
+			fieldInfo.OmitEmpty = tag.HasOption(sqlstrings.OmitEmptyOption)
+			fieldInfo.Secret = tag.HasOption(sqlstrings.SecretOption)
 			fields = append(fields, fieldInfo)
 		}
 
 	}
 
+	relations, err := mapRelations(nonRefItemType)
+	if err != nil {
+		return nil, err
+	}
+
+	var pk []*FieldInfo
+	for _, fieldInfo := range fields {
+		if fieldInfo.PK {
+			pk = append(pk, fieldInfo)
+		}
+	}
+
 	return &TypeMap{
 		NonRefType: nonRefItemType,
 		Fields:     fields,
 		TagName:    tagName,
+		Relations:  relations,
+		PK:         pk,
 	}, nil
 }
 
@@ -125,6 +201,8 @@ func IsScannable(t reflect.Type) bool {
 		return true
 	case *string:
 		return true
+	case *time.Time:
+		return true
 	default:
 		return reflect.PointerTo(t).Implements(scannable)
 	}
@@ -133,6 +211,10 @@ func IsScannable(t reflect.Type) bool {
 func (mapper *Mapper) Map(item reflect.Type, tagName string) (*TypeMap, error) {
 	nonRefType := ConversionTypeToNonRefType(item)
 
+	if len(tagName) == 0 {
+		tagName = sqlstrings.StdTagName
+	}
+
 	var err error
 	mapper.cacheLock.RLock()
 	typeMap, ok := mapper.cacheMaps[nonRefType]
@@ -157,13 +239,13 @@ func (sc *StdScanner) Scan(dest any, rows RowScanner, queryConfig sqlstrings.Que
 
 	ogSliceType := reflect.TypeOf(dest)
 	if ogSliceType.Kind() != reflect.Pointer {
-		return errors.New("dest must be a pointer to slice")
+		return fmt.Errorf("%w: dest must be a pointer to slice", ErrInvalidDest)
 	}
 
 	nonRefSliceType := ogSliceType.Elem()
 
 	if nonRefSliceType.Kind() != reflect.Slice {
-		return errors.New("dest must be a slice")
+		return fmt.Errorf("%w: dest must be a slice", ErrInvalidDest)
 	}
 
 	sliceVal := reflect.ValueOf(dest).Elem()
@@ -179,19 +261,49 @@ func (sc *StdScanner) Scan(dest any, rows RowScanner, queryConfig sqlstrings.Que
 		return err
 	}
 
+	fields, columns, err := sc.columnFields(rows, typeMap, queryConfig)
+	if err != nil {
+		return err
+	}
+
 	for rows.Next() {
 		itemZero := reflect.New(nonRefType)
-		err := rows.Scan(GetFieldsPointersOfItem(itemZero, typeMap, queryConfig.ExcludedTags)...)
-		if err == nil {
-			var ogVal any
-			ogVal, err = ConversionToOgType(itemZero.Elem().Interface(), ogType)
-			if err == nil {
-				sliceVal.Set(reflect.Append(sliceVal, reflect.ValueOf(ogVal)))
-			}
+		err = sc.scanRow(itemZero, rows, typeMap, queryConfig, fields, columns)
+		if err != nil {
+			return err
 		}
+		var ogVal any
+		ogVal, err = ConversionToOgType(itemZero.Elem().Interface(), ogType)
+		if err != nil {
+			return err
+		}
+		sliceVal.Set(reflect.Append(sliceVal, reflect.ValueOf(ogVal)))
+	}
+
+	return nil
+}
+
+// Сканирует текущую строку rows в dest, dest должен быть указателем на структуру, rows.Next() должен быть вызван заранее
+// ===================================================================================================
+// Scans the current row of rows into dest, dest should be a pointer to the struct, rows.Next() must be called beforehand
+func (sc *StdScanner) ScanRow(dest any, rows RowScanner, queryConfig sqlstrings.QueryConfig) error {
+	item := reflect.ValueOf(dest)
+	if item.Kind() != reflect.Pointer || item.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: dest must be a pointer to the struct", ErrInvalidDest)
+	}
+
+	typeMap, err := sc.Mapper.Map(item.Type(), queryConfig.TagName)
+
+	if err != nil {
+		return err
+	}
+
+	fields, columns, err := sc.columnFields(rows, typeMap, queryConfig)
+	if err != nil {
+		return err
 	}
 
-	return err
+	return sc.scanRow(item, rows, typeMap, queryConfig, fields, columns)
 }
 
 // Get pointers to fields of item, then give it in rows.Scan(), here you need to pass a pointer to the structure
@@ -208,23 +320,80 @@ func GetFieldsPointersOfItem(item reflect.Value, tmap *TypeMap, excludedTags []s
 
 	for _, fieldInfo := range tmap.Fields {
 		if !slices.Contains(excludedTags, fieldInfo.FTag) {
-			field := v.FieldByName(fieldInfo.Name)
-			if fieldInfo.Ftype.Kind() == reflect.Pointer {
-				if field.CanSet() {
-					field.Set(reflect.New(fieldInfo.Ftype.Elem()))
-				}
-				pointers = append(pointers, field.Interface())
-			} else if field.CanAddr() {
-				pointers = append(pointers, field.Addr().Interface())
+			if pointer := fieldPointer(v, fieldInfo); pointer != nil {
+				pointers = append(pointers, pointer)
+			}
+		}
+	}
+
+	return pointers
+}
+
+// Возвращает указатели на поля item (указатель на структуру) в порядке столбцов columns, для столбцов без поля возвращается указатель на значение, которое отбрасывается
+// ===================================================================================================
+// Returns pointers to the fields of item (a pointer to the struct) in the order of the columns, for columns without a field a pointer to a discarded value is returned
+// Имена сравниваются как в Scan: сначала точно, затем без учета регистра / Names are compared as in Scan: exactly first, then case-insensitively
+func GetColumnsPointersOfItem(item reflect.Value, tmap *TypeMap, columns []string) []any {
+	pointers := make([]any, len(columns))
+
+	for idx, column := range columns {
+		var pointer any
+		if item.Kind() == reflect.Pointer && !item.IsNil() {
+			if fieldIdx := columnField(tmap, column); fieldIdx >= 0 {
+				pointer = fieldPointer(item.Elem(), tmap.Fields[fieldIdx])
 			}
 		}
+		if pointer == nil {
+			pointer = new(any)
+		}
+		pointers[idx] = pointer
 	}
 
 	return pointers
 }
 
+// Возвращает указатель на поле для rows.Scan, поле-указатель инициализируется новым значением
+func fieldPointer(v reflect.Value, fieldInfo *FieldInfo) any {
+	field := v.FieldByIndex(fieldInfo.Index)
+	if fieldInfo.Ftype.Kind() == reflect.Pointer {
+		if field.CanSet() {
+			field.Set(reflect.New(fieldInfo.Ftype.Elem()))
+		}
+		return field.Interface()
+	}
+	if field.CanAddr() {
+		return field.Addr().Interface()
+	}
+	return nil
+}
+
+// Возвращает значения полей queryConfig.Item в порядке столбцов запроса типа queryConfig.QueryType, с учетом ExcludedTags и опций тега
+// ======================================================================================
+// Returns the values of the queryConfig.Item fields in the column order of a query of the queryConfig.QueryType type, taking ExcludedTags and the tag options into account
 func GetFieldsValuesOfItem(queryConfig sqlstrings.QueryConfig, tmap *TypeMap) []any {
+	args, _ := getFieldsValuesOfItem(queryConfig, tmap, true)
+	return args
+}
+
+// Как GetFieldsValuesOfItem, но без учета omitempty, для sqlstrings.GetInsertManyQuery
+// ======================================================================================
+// Same as GetFieldsValuesOfItem but without omitempty, for sqlstrings.GetInsertManyQuery
+func GetInsertManyValuesOfItem(queryConfig sqlstrings.QueryConfig, tmap *TypeMap) []any {
+	args, _ := getFieldsValuesOfItem(queryConfig, tmap, false)
+	return args
+}
+
+// Возвращает столбцы значений GetFieldsValuesOfItem (omitEmpty) или GetInsertManyValuesOfItem в том же порядке
+// ======================================================================================
+// Returns the columns of the GetFieldsValuesOfItem (omitEmpty) or GetInsertManyValuesOfItem values in the same order
+func GetFieldsColumnsOfItem(queryConfig sqlstrings.QueryConfig, tmap *TypeMap, omitEmpty bool) []string {
+	_, columns := getFieldsValuesOfItem(queryConfig, tmap, omitEmpty)
+	return columns
+}
+
+func getFieldsValuesOfItem(queryConfig sqlstrings.QueryConfig, tmap *TypeMap, omitEmpty bool) ([]any, []string) {
 	var args []any
+	var argColumns []string
 
 	GetNonRefVal := func(val reflect.Value) reflect.Value {
 		t := val.Type()
@@ -236,38 +405,61 @@ func GetFieldsValuesOfItem(queryConfig sqlstrings.QueryConfig, tmap *TypeMap) []
 	}
 
 	if queryConfig.Item == nil {
-		return args
+		return args, argColumns
 	}
 
 	val := GetNonRefVal(reflect.ValueOf(queryConfig.Item))
 
+	// при ненумерованных плейсхолдерах (?) аргумент для WHERE должен идти последним
+	var whereArg []any
+	var whereColumn []string
+
 	if len(queryConfig.ColumnName) > 0 && queryConfig.QueryType == sqlstrings.UPDATE {
 		idx := slices.IndexFunc(tmap.Fields, func(f *FieldInfo) bool { return f.FTag == queryConfig.ColumnName })
 		if idx >= 0 {
 			fieldInfo := tmap.Fields[idx]
-			field := val.FieldByName(fieldInfo.Name)
+			field := val.FieldByIndex(fieldInfo.Index)
 			if field.Kind() == reflect.Pointer && !field.IsNil() {
-				args = append(args, field.Elem().Interface())
+				whereArg = append(whereArg, field.Elem().Interface())
 			} else {
-				args = append(args, field.Interface())
+				whereArg = append(whereArg, field.Interface())
 			}
+			whereColumn = append(whereColumn, queryConfig.ColumnName)
 			queryConfig.ExcludedTags = append(queryConfig.ExcludedTags, queryConfig.ColumnName)
 		}
 
 	}
 
+	if queryConfig.Dialect.NumberedPlaceholders() {
+		args = append(args, whereArg...)
+		argColumns = append(argColumns, whereColumn...)
+	}
+
+	// столбцы, которые записывает запрос, определяются так же как и в генераторах sqlstrings
+	queryConfig.TagName = tmap.TagName
+	columns := map[string]bool{}
+	for _, field := range sqlstrings.QueryFields(queryConfig, queryConfig.QueryType, omitEmpty) {
+		columns[field.Column] = true
+	}
+
 	for _, fieldInfo := range tmap.Fields {
-		if !slices.Contains(queryConfig.ExcludedTags, fieldInfo.FTag) {
-			field := val.FieldByName(fieldInfo.Name)
+		if columns[fieldInfo.FTag] {
+			field := val.FieldByIndex(fieldInfo.Index)
 			if field.Kind() == reflect.Pointer && !field.IsNil() {
 				args = append(args, field.Elem().Interface())
 			} else {
 				args = append(args, field.Interface())
 			}
+			argColumns = append(argColumns, fieldInfo.FTag)
 		}
 	}
 
-	return args
+	if !queryConfig.Dialect.NumberedPlaceholders() {
+		args = append(args, whereArg...)
+		argColumns = append(argColumns, whereColumn...)
+	}
+
+	return args, argColumns
 }
 
 // Conversion to the original type, it can be *User, but I can only get fields from the type from User, and I need to return *User back
@@ -282,7 +474,7 @@ func ConversionToOgType(item any, og reflect.Type) (any, error) {
 	}
 
 	if og.Kind() != reflect.Pointer {
-		return nil, errors.New("type need to be either struct or pointer to the struct...")
+		return nil, fmt.Errorf("%w, got %s", ErrUnmappableType, og)
 	}
 
 	v := reflect.New(t)
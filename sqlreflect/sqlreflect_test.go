@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
 )
@@ -481,4 +482,99 @@ func TestGetFieldsValuesOfItem(t *testing.T) {
 		t.Errorf("user Name3 not match")
 	}
 
+	qc.Dialect = sqlstrings.MYSQL
+
+	userFiels2 = GetFieldsValuesOfItem(qc, typeMap)
+	if len(userFiels2) != 4 {
+		t.Errorf("GetFieldsValuesOfItem3 failed")
+	}
+
+	if string(userFiels2[3].([]byte)) != string(bsn) || userFiels2[0].(string) != nstr {
+		t.Errorf("where argument must be the last one for positional placeholders")
+	}
+
+}
+
+type Audit struct {
+	CreatedAt time.Time `db:"CreatedAt"`
+}
+
+type nestedAddress struct {
+	City string `db:"city"`
+}
+
+type nestedUser struct {
+	Audit
+	Id      int           `db:"Id"`
+	Address nestedAddress `db:"addr_,prefix"`
+}
+
+func TestNestedMapping(t *testing.T) {
+	typeMap, err := MapFunc(reflect.TypeFor[nestedUser](), "db")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tags []string
+	for _, field := range typeMap.Fields {
+		tags = append(tags, field.FTag)
+	}
+
+	if !slices.Equal(tags, []string{"CreatedAt", "Id", "addr_city"}) {
+		t.Errorf("tags not match %v", tags)
+	}
+
+	created := time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC)
+	item := nestedUser{Audit: Audit{CreatedAt: created}, Id: 7, Address: nestedAddress{City: "Rostov"}}
+
+	args := GetFieldsValuesOfItem(sqlstrings.QueryConfig{Item: item}, typeMap)
+	if len(args) != 3 || args[0] != created || args[2] != "Rostov" {
+		t.Errorf("values not match %v", args)
+	}
+
+	var scanned nestedUser
+	pointers := GetFieldsPointersOfItem(reflect.ValueOf(&scanned), typeMap, nil)
+	*pointers[0].(*time.Time) = created
+	*pointers[2].(*string) = "Moscow"
+
+	if scanned.CreatedAt != created || scanned.Address.City != "Moscow" {
+		t.Errorf("pointers not match %#v", scanned)
+	}
+}
+
+type optionsUser struct {
+	Id      int    `db:"Id,pk,auto"`
+	Login   string `db:"Login,insert"`
+	Name    string `db:"Name,omitempty"`
+	Version int    `db:"Version,readonly"`
+}
+
+func TestTagOptionsMapping(t *testing.T) {
+	typeMap, err := MapFunc(reflect.TypeFor[optionsUser](), "db")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	id, login, name := typeMap.Fields[0], typeMap.Fields[1], typeMap.Fields[2]
+	if !id.PK || !id.Auto || !login.InsertOnly || !name.OmitEmpty || !typeMap.Fields[3].ReadOnly || login.PK {
+		t.Errorf("options not match %+v %+v %+v", id, login, name)
+	}
+
+	item := optionsUser{Id: 5, Login: "login", Version: 3}
+
+	args := GetFieldsValuesOfItem(sqlstrings.QueryConfig{Item: item, QueryType: sqlstrings.INSERT}, typeMap)
+	if !slices.Equal(args, []any{"login"}) {
+		t.Errorf("insert values not match %v", args)
+	}
+
+	args = GetInsertManyValuesOfItem(sqlstrings.QueryConfig{Item: item, QueryType: sqlstrings.INSERT}, typeMap)
+	if !slices.Equal(args, []any{"login", ""}) {
+		t.Errorf("insert many values not match %v", args)
+	}
+
+	item.Name = "name"
+	args = GetFieldsValuesOfItem(sqlstrings.QueryConfig{Item: item, QueryType: sqlstrings.UPDATE, ColumnName: "Id", Dialect: sqlstrings.POSTGRES}, typeMap)
+	if !slices.Equal(args, []any{5, "name"}) {
+		t.Errorf("update values not match %v", args)
+	}
 }
@@ -0,0 +1,124 @@
+package sqlstrings
+
+import (
+	"strconv"
+	"strings"
+)
+
+//region Dialect
+
+// Диалект SQL, определяет вид плейсхолдеров, экранирование имен и способ возврата значений из INSERT
+// DEFAULTDIALECT ведет себя как POSTGRES, но имена оборачиваются в NameWrapper как есть, остальные диалекты всегда экранируют имена
+// ======================================================================================
+// SQL dialect, defines placeholders, quoting of names and the way values are returned from INSERT
+// DEFAULTDIALECT behaves like POSTGRES, but names are wrapped into NameWrapper as is, the other dialects always quote names
+type Dialect int
+
+const (
+	DEFAULTDIALECT Dialect = iota
+	POSTGRES
+	MYSQL
+	SQLITE
+	SQLSERVER
+)
+
+// Возвращает плейсхолдер для аргумента с номером n (нумерация с 1)
+// ======================================================================================
+// Returns the placeholder for the argument number n (numbering starts from 1)
+func (d Dialect) Placeholder(n int) string {
+	switch d {
+	case MYSQL:
+		return "?"
+	case SQLITE:
+		return "?" + strconv.Itoa(n)
+	case SQLSERVER:
+		return "@p" + strconv.Itoa(n)
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// Возвращает true если плейсхолдеры нумерованные, иначе аргументы связываются в порядке появления в строке запроса
+// ======================================================================================
+// Returns true if placeholders are numbered, otherwise arguments are bound in the order they appear in the query string
+func (d Dialect) NumberedPlaceholders() bool {
+	return d != MYSQL
+}
+
+// Возвращает true если INSERT может вернуть значения столбцов (RETURNING или OUTPUT INSERTED)
+// ======================================================================================
+// Returns true if INSERT is able to return column values (RETURNING or OUTPUT INSERTED)
+func (d Dialect) SupportsReturning() bool {
+	return d != MYSQL
+}
+
+// Максимальное количество аргументов в одном запросе
+// ======================================================================================
+// Maximum number of arguments in a single query
+func (d Dialect) MaxParams() int {
+	switch d {
+	case SQLITE:
+		return 32766
+	case SQLSERVER:
+		return 2100
+	}
+	return 65535
+}
+
+// Максимальное количество наборов значений в одном INSERT, 0 если ограничения нет
+// ======================================================================================
+// Maximum number of value tuples in a single INSERT, 0 if there is no limit
+func (d Dialect) MaxInsertRows() int {
+	if d == SQLSERVER {
+		return 1000
+	}
+	return 0
+}
+
+// Экранирует имя таблицы или столбца родными для диалекта символами
+// ======================================================================================
+// Quotes the table or column name with the dialect's native characters
+func (d Dialect) Quote(name string) string {
+	switch d {
+	case MYSQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case SQLSERVER:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+}
+
+// Возвращает выражение для возврата столбцов после INSERT, для SQLSERVER оно ставится перед VALUES, для остальных в конец строки
+// ======================================================================================
+// Returns the clause that returns columns after INSERT, for SQLSERVER it is placed before VALUES, for the others at the end of the string
+func (d Dialect) returningClause(columns []string) string {
+	if len(columns) == 0 || !d.SupportsReturning() {
+		return ""
+	}
+	if d == SQLSERVER {
+		return " OUTPUT INSERTED." + strings.Join(columns, ", INSERTED.")
+	}
+	return " RETURNING " + strings.Join(columns, ", ")
+}
+
+//endregion
+
+//region QueryConfig dialect helpers
+
+// При явно указанном диалекте имя всегда экранируется его символами, для DEFAULTDIALECT оборачивается в NameWrapper, если он указан
+// ======================================================================================
+// When a dialect is set explicitly the name is always quoted with its characters, for DEFAULTDIALECT it is wrapped into NameWrapper if it is specified
+func (q QueryConfig) wrap(name string) string {
+	if q.Dialect != DEFAULTDIALECT {
+		return q.Dialect.Quote(name)
+	}
+	if len(q.NameWrapper) == 0 {
+		return name
+	}
+	return WrapNigger(name, q.NameWrapper)
+}
+
+func (q QueryConfig) placeholder(n int) string {
+	return q.Dialect.Placeholder(n)
+}
+
+//endregion
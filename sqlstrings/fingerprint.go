@@ -0,0 +1,109 @@
+package sqlstrings
+
+import (
+	"regexp"
+	"strings"
+)
+
+//region Fingerprint
+
+var (
+	inListRegexp = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	valuesRegexp = regexp.MustCompile(`(?i)\bVALUES\s*\(\s*\?(?:\s*,\s*\?)*\s*\)(?:\s*,\s*\(\s*\?(?:\s*,\s*\?)*\s*\))*`)
+)
+
+// Возвращает отпечаток запроса: строковые и числовые литералы и плейсхолдеры ($1, ?1, @p1, ?) заменяются на ?,
+// списки IN (...) и наборы VALUES (...), (...) сворачиваются, пробелы схлопываются. Имена в кавычках не меняются
+// Запросы, отличающиеся только значениями или количеством элементов списков, получают одинаковый отпечаток
+// ======================================================================================
+// Returns the fingerprint of the query: string and numeric literals and placeholders ($1, ?1, @p1, ?) are replaced with ?,
+// IN (...) lists and VALUES (...), (...) tuples are collapsed, whitespace is squashed. Quoted names are not changed
+// Queries that differ only in values or in the number of list elements get the same fingerprint
+func Fingerprint(query string) string {
+	var builder strings.Builder
+	builder.Grow(len(query))
+
+	space := false
+	for i := 0; i < len(query); {
+		c := query[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+			continue
+		}
+
+		if space && builder.Len() > 0 {
+			builder.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i, '\'')
+			builder.WriteByte('?')
+		case c == '"' || c == '`':
+			end := skipQuoted(query, i, c)
+			builder.WriteString(query[i:end])
+			i = end
+		case c == '[':
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				end = len(query) - i - 1
+			}
+			builder.WriteString(query[i : i+end+1])
+			i += end + 1
+		case c == '$' || c == '?' || (c == '@' && i+1 < len(query) && query[i+1] == 'p'):
+			i++
+			if c == '@' {
+				i++
+			}
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			builder.WriteByte('?')
+		case isDigit(c) && !isIdentPart(builder.String()):
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+			builder.WriteByte('?')
+		default:
+			builder.WriteByte(c)
+			i++
+		}
+	}
+
+	res := inListRegexp.ReplaceAllString(builder.String(), "IN (...)")
+	return valuesRegexp.ReplaceAllString(res, "VALUES (...)")
+}
+
+// Возвращает индекс после закрывающей кавычки, удвоенная кавычка считается экранированной
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Продолжает ли цифра идентификатор, например Table1
+func isIdentPart(written string) bool {
+	if len(written) == 0 {
+		return false
+	}
+	c := written[len(written)-1]
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+//endregion
@@ -0,0 +1,28 @@
+package sqlstrings
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT Id, Name FROM users WHERE Id = $1", "SELECT Id, Name FROM users WHERE Id = ?"},
+		{"select *  from\n\tusers where name = 'O''Brien' and age > 42.5", "select * from users where name = ? and age > ?"},
+		{"SELECT Id FROM Table1 WHERE Id IN ($1, $2, $3)", "SELECT Id FROM Table1 WHERE Id IN (...)"},
+		{"SELECT Id FROM Table1 WHERE Id IN (?)", "SELECT Id FROM Table1 WHERE Id IN (...)"},
+		{"INSERT INTO users (Name, Password) VALUES ($1,$2),($3,$4),($5,$6) RETURNING Id", "INSERT INTO users (Name, Password) VALUES (...) RETURNING Id"},
+		{"INSERT INTO [users] ([Name]) OUTPUT INSERTED.[Id] VALUES (@p1)", "INSERT INTO [users] ([Name]) OUTPUT INSERTED.[Id] VALUES (...)"},
+		{"UPDATE \"users 2\" SET `Name` = ?1 WHERE \"Id\" = ?2", "UPDATE \"users 2\" SET `Name` = ? WHERE \"Id\" = ?"},
+	}
+
+	for _, c := range cases {
+		if res := Fingerprint(c.query); res != c.expected {
+			t.Errorf("FINGERPRINTS NOT MATCH\n%s\n%s", c.expected, res)
+		}
+	}
+
+	if Fingerprint("DELETE FROM users WHERE Id IN (1, 2)") != Fingerprint("DELETE FROM users WHERE Id IN (3)") {
+		t.Errorf("IN lists of different length must have the same fingerprint")
+	}
+}
@@ -0,0 +1,190 @@
+package sqlstrings
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Положение NULL при сортировке, NULLSDEFAULT - поведение базы данных по умолчанию
+// ======================================================================================
+// Position of NULL values when sorting, NULLSDEFAULT - default behaviour of the database
+type Nulls int
+
+const (
+	NULLSDEFAULT Nulls = iota
+	NULLSFIRST
+	NULLSLAST
+)
+
+// Column - тег поля структуры Item, по которому выполняется сортировка ;
+// Desc - сортировка по убыванию ;
+// Nulls - положение NULL, для MYSQL и SQLSERVER эмулируется через CASE ;
+// ======================================================================================
+// Column - tag of the Item struct field used for sorting ;
+// Desc - descending order ;
+// Nulls - position of NULL values, for MYSQL and SQLSERVER it is emulated with CASE ;
+type Order struct {
+	Column string
+	Desc   bool
+	Nulls  Nulls
+}
+
+// Сортировка по возрастанию / Ascending order
+func Asc(column string) Order {
+	return Order{Column: column}
+}
+
+// Сортировка по убыванию / Descending order
+func Desc(column string) Order {
+	return Order{Column: column, Desc: true}
+}
+
+func (o Order) NullsFirst() Order {
+	o.Nulls = NULLSFIRST
+	return o
+}
+
+func (o Order) NullsLast() Order {
+	o.Nulls = NULLSLAST
+	return o
+}
+
+//region Order validation
+
+// Проверяет что все столбцы сортировки являются тегами полей params.Item, т.к. имена столбцов попадают в строку запроса как есть
+// ======================================================================================
+// Checks that all sort columns are tags of the params.Item fields, because column names get into the query string as is
+func ValidateOrderBy(params QueryConfig) error {
+	if len(params.OrderBy) == 0 {
+		return nil
+	}
+
+	if params.Item == nil {
+		return errors.New("queryConfig parameter Item must be specified for OrderBy")
+	}
+
+	tags := itemTags(params)
+
+	for _, order := range params.OrderBy {
+		if !slices.Contains(tags, order.Column) {
+			return errors.New("unknown sort column " + strconv.Quote(order.Column))
+		}
+	}
+
+	return nil
+}
+
+//endregion
+
+//region Order rendering
+
+// Дописывает ORDER BY, LIMIT и OFFSET, столбцы не являющиеся тегами Item пропускаются
+// SQLSERVER использует OFFSET ... ROWS FETCH NEXT ... ROWS ONLY, который требует ORDER BY
+func writeOrderBy(builder *strings.Builder, params QueryConfig) {
+	var orders []Order
+
+	if len(params.OrderBy) > 0 {
+		tags := itemTags(params)
+		for _, order := range params.OrderBy {
+			if slices.Contains(tags, order.Column) {
+				orders = append(orders, order)
+			}
+		}
+	}
+
+	paging := params.Limit > 0 || params.Offset > 0
+
+	if len(orders) > 0 {
+		builder.WriteString(" ORDER BY ")
+		for idx, order := range orders {
+			if idx > 0 {
+				builder.WriteString(", ")
+			}
+			writeOrder(builder, params, order)
+		}
+	} else if paging && params.Dialect == SQLSERVER {
+		builder.WriteString(" ORDER BY (SELECT NULL)")
+	}
+
+	if !paging {
+		return
+	}
+
+	limit := strconv.Itoa(params.Limit)
+	offset := strconv.Itoa(params.Offset)
+
+	switch params.Dialect {
+	case SQLSERVER:
+		builder.WriteString(" OFFSET " + offset + " ROWS")
+		if params.Limit > 0 {
+			builder.WriteString(" FETCH NEXT " + limit + " ROWS ONLY")
+		}
+		return
+	case MYSQL:
+		// MYSQL не поддерживает OFFSET без LIMIT
+		if params.Limit <= 0 {
+			limit = "18446744073709551615"
+		}
+	case SQLITE:
+		if params.Limit <= 0 {
+			limit = "-1"
+		}
+	default:
+		if params.Limit <= 0 {
+			limit = ""
+		}
+	}
+
+	if len(limit) > 0 {
+		builder.WriteString(" LIMIT " + limit)
+	}
+	if params.Offset > 0 {
+		builder.WriteString(" OFFSET " + offset)
+	}
+}
+
+func writeOrder(builder *strings.Builder, params QueryConfig, order Order) {
+	column := params.wrap(order.Column)
+
+	emulateNulls := params.Dialect == MYSQL || params.Dialect == SQLSERVER
+
+	if emulateNulls && order.Nulls != NULLSDEFAULT {
+		if order.Nulls == NULLSFIRST {
+			builder.WriteString("CASE WHEN " + column + " IS NULL THEN 0 ELSE 1 END, ")
+		} else {
+			builder.WriteString("CASE WHEN " + column + " IS NULL THEN 1 ELSE 0 END, ")
+		}
+	}
+
+	builder.WriteString(column)
+
+	if order.Desc {
+		builder.WriteString(" DESC")
+	} else {
+		builder.WriteString(" ASC")
+	}
+
+	if emulateNulls {
+		return
+	}
+
+	switch order.Nulls {
+	case NULLSFIRST:
+		builder.WriteString(" NULLS FIRST")
+	case NULLSLAST:
+		builder.WriteString(" NULLS LAST")
+	}
+}
+
+// Строка для ключа кэша
+func getOrderByKey(orders []Order) string {
+	var builder strings.Builder
+	for _, order := range orders {
+		builder.WriteString(strconv.Quote(order.Column) + "," + strconv.FormatBool(order.Desc) + "," + strconv.Itoa(int(order.Nulls)) + ";")
+	}
+	return builder.String()
+}
+
+//endregion
@@ -0,0 +1,47 @@
+package sqlstrings
+
+import (
+	"testing"
+)
+
+func TestOrderByQuery(t *testing.T) {
+	query := QueryConfig{
+		TableName:    tableName,
+		Item:         user2{},
+		ExcludedTags: []string{"Id"},
+		OrderBy:      []Order{Desc("Name").NullsLast(), Asc("Id")},
+		Limit:        10,
+		Offset:       20,
+	}
+
+	cases := []struct {
+		name     string
+		res      string
+		expected string
+	}{
+		{"postgres", GetSelectQuery(query), selectQuery1 + " ORDER BY Name DESC NULLS LAST, Id ASC LIMIT 10 OFFSET 20"},
+		{"where", GetSelectQuery(query.ChangeWhere(Eq("Name", "bob"))), selectQuery1 + " WHERE Name = $1 ORDER BY Name DESC NULLS LAST, Id ASC LIMIT 10 OFFSET 20"},
+		{"mysql", GetSelectQuery(query.ChangeDialect(MYSQL).ChangeLimit(0, 5)), "SELECT `Name`, `Password`, `Description` FROM `users` ORDER BY CASE WHEN `Name` IS NULL THEN 1 ELSE 0 END, `Name` DESC, `Id` ASC LIMIT 18446744073709551615 OFFSET 5"},
+		{"sqlite", GetSelectQuery(query.ChangeDialect(SQLITE).ChangeLimit(0, 5)), "SELECT \"Name\", \"Password\", \"Description\" FROM \"users\" ORDER BY \"Name\" DESC NULLS LAST, \"Id\" ASC LIMIT -1 OFFSET 5"},
+		{"sqlserver", GetSelectQuery(query.ChangeDialect(SQLSERVER)), "SELECT [Name], [Password], [Description] FROM [users] ORDER BY CASE WHEN [Name] IS NULL THEN 1 ELSE 0 END, [Name] DESC, [Id] ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{"sqlserver no order", GetSelectQuery(query.ChangeDialect(SQLSERVER).ChangeOrderBy()), "SELECT [Name], [Password], [Description] FROM [users] ORDER BY (SELECT NULL) OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{"limit only", GetSelectQuery(query.ChangeOrderBy().ChangeLimit(5, 0)), selectQuery1 + " LIMIT 5"},
+		{"unknown column", GetSelectQuery(query.ChangeOrderBy(Asc("Name; DROP TABLE users")).ChangeLimit(0, 0)), selectQuery1},
+		{"cached", GetSelectQueryCached(query), selectQuery1 + " ORDER BY Name DESC NULLS LAST, Id ASC LIMIT 10 OFFSET 20"},
+		{"cached other page", GetSelectQueryCached(query.ChangeLimit(10, 30)), selectQuery1 + " ORDER BY Name DESC NULLS LAST, Id ASC LIMIT 10 OFFSET 30"},
+	}
+
+	for _, c := range cases {
+		if c.res != c.expected {
+			t.Errorf("%s: QUERIES NOT MATCH\n%s\n%s", c.name, c.expected, c.res)
+		}
+	}
+
+	if err := ValidateOrderBy(query); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if err := ValidateOrderBy(query.ChangeOrderBy(Asc("Roles"))); err == nil {
+		t.Errorf("untagged column must not be accepted")
+	}
+}
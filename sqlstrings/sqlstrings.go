@@ -4,7 +4,6 @@ import (
 	"reflect"
 	"slices"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 )
@@ -20,6 +19,7 @@ const (
 	UPDATE
 	SELECT
 	DELETE
+	UPSERT
 )
 
 // TableName - Имя таблицы, если оно не указано тогда в качестве имени таблицы будет использовано имя типа структуры поля ItemToAdd
@@ -31,6 +31,12 @@ const (
 // TagName - нужен для того если вы используете нестандартный тег для полей структуры, тогда вместо стандартного dbcn будет использоваться указанный тег ;
 // ItemToAdd - структура содержащая поля с тегами, значение которых соответсвует названиям столбцов таблицы ;
 // ExcludedTags - список тегов которые вы хотите исключить при созданнии строки, например Id, тогда конечная строка не будет содержать данного столбца ;
+// Where - (Select, Update, Delete) дополнительное условие, объединяется с ColumnName через AND, аргументы условия идут после всех остальных аргументов ;
+// OnConflict - (Upsert) столбцы конфликта и действие при конфликте, DO NOTHING или обновление столбцов ;
+// Dialect - диалект SQL, определяет вид плейсхолдеров ($1, ?, ?1, @p1), экранирование имен (для DEFAULTDIALECT - NameWrapper) и RETURNING/OUTPUT INSERTED ;
+// OrderBy - (Select) сортировка, столбцы должны быть тегами полей Item, иначе они пропускаются ;
+// Limit, Offset - (Select) ограничение количества строк и смещение, 0 - не используется ;
+// Returning - (Insert, Update, Upsert) столбцы для RETURNING (OUTPUT INSERTED для SQLSERVER), при указании заменяют ColumnName в INSERT, значения записываются в поля Item, а Insert и Upsert возвращают значение ReturningColumn, если он среди них ;
 // =========================================================================================================================================================
 // TableName is the name of the table, if it is not specified, then the name of the ItemToAdd field structure type will be used as the table name
 // NameWrapper is needed to wrap the names of columns and tables, if you specify, for example with  "  then the name will be "SomeName"
@@ -41,6 +47,12 @@ const (
 // TagName is needed so that if you use a non-standard tag for the fields of the structure, then the specified tag will be used instead of the standard dbcn ;
 // ItemToAdd - a structure containing fields with tags, the value of which is corresponds to the column names of the table ;
 // ExcludedTags - a list of tags that you want to exclude when creating a row, for example, Id, then the final row will not contain this column. ;
+// Where - (Select, Update, Delete) additional condition, it is combined with ColumnName by AND, the arguments of the condition go after all the other arguments ;
+// OnConflict - (Upsert) conflict columns and the action on conflict, DO NOTHING or update of columns ;
+// Dialect - SQL dialect, defines placeholders ($1, ?, ?1, @p1), quoting of names (NameWrapper for DEFAULTDIALECT) and RETURNING/OUTPUT INSERTED ;
+// OrderBy - (Select) sorting, the columns must be tags of the Item fields, otherwise they are skipped ;
+// Limit, Offset - (Select) limit of the number of rows and offset, 0 - not used ;
+// Returning - (Insert, Update, Upsert) columns for RETURNING (OUTPUT INSERTED for SQLSERVER), when specified they replace ColumnName in INSERT, the values are written into the Item fields, Insert and Upsert return the ReturningColumn value if it is among them ;
 type QueryConfig struct {
 	TableName    string
 	NameWrapper  string
@@ -49,6 +61,13 @@ type QueryConfig struct {
 	Item         any
 	ExcludedTags []string
 	QueryType    QueryType
+	Dialect      Dialect
+	OnConflict   OnConflict
+	Where        Condition
+	OrderBy      []Order
+	Limit        int
+	Offset       int
+	Returning    []string
 }
 
 // Возвращает строку указанного типа /
@@ -63,6 +82,8 @@ func GetQuery(params QueryConfig) string {
 		return GetSelectQuery(params)
 	case DELETE:
 		return GetDeleteQuery(params)
+	case UPSERT:
+		return GetUpsertQuery(params)
 	}
 	return "this query type is not supported"
 }
@@ -73,12 +94,24 @@ func GetQuery(params QueryConfig) string {
 // ============================================================================================================================================================
 // Returns the INSERT INTO TableName (ItemFieldTag1, ItemFieldTag2 ...) VALUES ($1,$2 ...) [RETURNING ColumnName] query string ... If columnName is specified, then the following is added to the end of the line: RETURNING IdColumnName, the order of the arguments must match the order of the fields in the passed structure.
 func GetInsertQuery(params QueryConfig) string {
-	var builder strings.Builder
+	return getInsertQuery(params, 1, true)
+}
 
-	tagName := StdTagName
+// Возвращает строку запроса INSERT INTO TableName (ItemFieldTag1, ItemFieldTag2 ...) VALUES ($1,$2 ...),($3,$4 ...) ... с rowsCount наборами значений, аргументы передаются подряд для каждой записи
+// Опция omitempty здесь не учитывается
+// ============================================================================================================================================================
+// Returns the INSERT INTO TableName (ItemFieldTag1, ItemFieldTag2 ...) VALUES ($1,$2 ...),($3,$4 ...) ... query string with rowsCount value tuples, the arguments are passed one record after another
+// The omitempty option is not applied here
+func GetInsertManyQuery(params QueryConfig, rowsCount int) string {
+	// у нескольких записей набор столбцов должен быть общим, поэтому omitempty не учитывается
+	return getInsertQuery(params, rowsCount, false)
+}
+
+func getInsertQuery(params QueryConfig, rowsCount int, omitEmpty bool) string {
+	var builder strings.Builder
 
-	if len(params.TagName) > 0 {
-		tagName = params.TagName
+	if rowsCount < 1 {
+		rowsCount = 1
 	}
 
 	if params.Item == nil {
@@ -87,10 +120,12 @@ func GetInsertQuery(params QueryConfig) string {
 
 	typeOfN := ConversionValToNonRefType(params.Item)
 
-	numFields := typeOfN.NumField()
+	columns := itemColumns(params, INSERT, omitEmpty)
+
+	numFields := len(columns)
 
 	additionalSymbols := 37
-	totalSymbols := len(params.TableName) + len(params.ColumnName) + additionalSymbols + numFields*(4+2*len(params.NameWrapper))
+	totalSymbols := len(params.TableName) + len(params.ColumnName) + additionalSymbols + numFields*(2*len(params.NameWrapper)) + rowsCount*numFields*4
 	//Выделяем память под символы сразу
 	builder.Grow(totalSymbols)
 
@@ -101,57 +136,46 @@ func GetInsertQuery(params QueryConfig) string {
 		tbname = typeOfN.Name()
 	}
 
-	if len(params.NameWrapper) > 0 {
-		tbname = WrapNigger(tbname, params.NameWrapper)
-	}
+	tbname = params.wrap(tbname)
 	builder.WriteString("INSERT INTO " + tbname + " (")
 
-	counter := 0
-
-	isFieldDb := false
-	isPrevFieldDb := isFieldDb
-
-	//Проходим по всем полям переданной структуры
-	for i := range numFields {
-		//Читаем значение тега
-		tag := typeOfN.Field(i).Tag.Get(tagName)
+	counter := len(columns)
 
-		isPrevFieldDb = isFieldDb
-		isFieldDb = len(tag) > 0 && !slices.Contains(params.ExcludedTags, tag)
-
-		if isFieldDb && isPrevFieldDb {
+	//Проходим по всем столбцам переданной структуры, поля без тега и теги из списка исключений уже отброшены
+	for i, column := range columns {
+		if i > 0 {
 			builder.WriteString(", ")
 		}
+		builder.WriteString(params.wrap(column))
+	}
 
-		//Если данное поле структуры имеет тег и тег не входит в список исключений, тогда добавляем содержимое тега в нашу строку НЕГРЫ! 14.01.2026
-		if isFieldDb {
-			name := tag
-			if len(params.NameWrapper) > 0 {
-				name = WrapNigger(name, params.NameWrapper)
-			}
-			builder.WriteString(name)
-			counter++
-		}
+	returning := params.wrapReturning()
+
+	builder.WriteString(")")
 
+	// SQL Server возвращает значения через OUTPUT INSERTED перед VALUES
+	if params.Dialect == SQLSERVER {
+		builder.WriteString(params.Dialect.returningClause(returning))
 	}
 
-	// формируем такую штуку VALUES ($1,$2 ....)
-	builder.WriteString(") VALUES (")
-	for idx := range counter {
-		if idx > 0 {
+	// формируем такую штуку VALUES ($1,$2 ....),($3,$4 ....)
+	builder.WriteString(" VALUES ")
+	for row := range rowsCount {
+		if row > 0 {
 			builder.WriteString(",")
 		}
-		builder.WriteString("$" + strconv.Itoa(idx+1))
+		builder.WriteString("(")
+		for idx := range counter {
+			if idx > 0 {
+				builder.WriteString(",")
+			}
+			builder.WriteString(params.placeholder(row*counter + idx + 1))
+		}
+		builder.WriteString(")")
 	}
 
-	builder.WriteString(")")
-
-	if len(params.ColumnName) > 0 {
-		idcolumn := params.ColumnName
-		if len(params.NameWrapper) > 0 {
-			idcolumn = WrapNigger(idcolumn, params.NameWrapper)
-		}
-		builder.WriteString(" RETURNING " + idcolumn)
+	if params.Dialect != SQLSERVER {
+		builder.WriteString(params.Dialect.returningClause(returning))
 	}
 	return builder.String()
 }
@@ -160,9 +184,9 @@ func GetInsertQuery(params QueryConfig) string {
 
 //region UpdateQuery
 
-// Возвращает строку типа UPDATE TableName SET ItemFieldTag1=$1, ItemFieldTag2=$2 ... [WHERE ColumnName = $1] , eсли вы передаете ColumnName, тогда в конец строки будет добавлено WHERE ColumnName = $1 и аргумент для него должен быть первым в списке аргументов, для остального порядок аргументов должен соотвествовать порядку полей в передаваемой структуре
+// Возвращает строку типа UPDATE TableName SET ItemFieldTag1=$1, ItemFieldTag2=$2 ... [WHERE ColumnName = $1] , eсли вы передаете ColumnName, тогда в конец строки будет добавлено WHERE ColumnName = $1 и аргумент для него должен быть первым в списке аргументов, для остального порядок аргументов должен соотвествовать порядку полей в передаваемой структуре. Для диалекта MYSQL (плейсхолдеры ?) аргумент для WHERE должен быть последним
 // ===============================================================================================================================
-// Returns a string like UPDATE TableName SET ColumnName1=$1  ItemFieldTag2=$2 ... [WHERE ColumnName = $1] , if you pass ColumnName, then WHERE ColumnName = $1 will be added to the end of the string and the argument for it must be the first in the argument list. For the rest, the order of the arguments must match the order of the fields in the passed structure
+// Returns a string like UPDATE TableName SET ColumnName1=$1  ItemFieldTag2=$2 ... [WHERE ColumnName = $1] , if you pass ColumnName, then WHERE ColumnName = $1 will be added to the end of the string and the argument for it must be the first in the argument list. For the rest, the order of the arguments must match the order of the fields in the passed structure. For the MYSQL dialect (? placeholders) the WHERE argument must be the last one
 func GetUpdateQuery(params QueryConfig) string {
 
 	if params.Item == nil {
@@ -171,67 +195,63 @@ func GetUpdateQuery(params QueryConfig) string {
 
 	typeOfN := ConversionValToNonRefType(params.Item)
 
+	columns := itemColumns(params, UPDATE, true)
+
 	counter := 0
 
-	numFields := typeOfN.NumField()
+	numFields := len(columns)
 
 	var builder strings.Builder
 	additionalSymbols := 11
 	totalSymbols := len(params.TableName) + len(params.ColumnName) + additionalSymbols + numFields*(4+2*len(params.NameWrapper))
 	builder.Grow(totalSymbols)
 
-	tagName := StdTagName
-
-	if len(params.TagName) > 0 {
-		tagName = params.TagName
-	}
-
 	tbname := params.TableName
 
 	if len(tbname) == 0 {
 		tbname = typeOfN.Name()
 	}
 
-	if len(params.NameWrapper) > 0 {
-		tbname = WrapNigger(tbname, params.NameWrapper)
-	}
+	tbname = params.wrap(tbname)
 
 	builder.WriteString("UPDATE " + tbname + " SET ")
 
 	adder := 1
 
-	if len(params.ColumnName) > 0 {
+	// при нумерованных плейсхолдерах аргумент для WHERE идет первым, иначе последним
+	if len(params.ColumnName) > 0 && params.Dialect.NumberedPlaceholders() {
 		adder = 2
 	}
 
-	isFieldDb := false
-	isPrevFieldDb := isFieldDb
-
-	for i := range numFields {
-		tag := typeOfN.Field(i).Tag.Get(tagName)
-
-		isPrevFieldDb = isFieldDb
-		isFieldDb = len(tag) > 0 && !slices.Contains(params.ExcludedTags, tag)
-
-		if isFieldDb && isPrevFieldDb {
+	for i, column := range columns {
+		if i > 0 {
 			builder.WriteString(", ")
 		}
+		builder.WriteString(params.wrap(column) + " = " + params.placeholder(counter+adder))
+		counter++
+	}
 
-		if isFieldDb {
-			if len(params.NameWrapper) > 0 {
-				tag = WrapNigger(tag, params.NameWrapper)
-			}
-			builder.WriteString(tag + " = $" + strconv.Itoa(counter+adder))
-			counter++
-		}
+	// в UPDATE возвращаются только столбцы Returning, ColumnName используется в WHERE
+	var returning []string
+	for _, column := range params.Returning {
+		returning = append(returning, params.wrap(column))
 	}
 
-	if len(params.ColumnName) > 0 {
-		filterColumnName := params.ColumnName
-		if len(params.NameWrapper) > 0 {
-			filterColumnName = WrapNigger(filterColumnName, params.NameWrapper)
-		}
-		builder.WriteString(" WHERE " + filterColumnName + " = $1")
+	// SQL Server возвращает значения через OUTPUT INSERTED перед WHERE
+	if params.Dialect == SQLSERVER {
+		builder.WriteString(params.Dialect.returningClause(returning))
+	}
+
+	columnIdx := 1
+	if !params.Dialect.NumberedPlaceholders() {
+		columnIdx = counter + 1
+	}
+
+	// аргументы условия Where всегда идут после аргументов SET и ColumnName
+	writeWhere(&builder, params, columnIdx, counter+adder)
+
+	if params.Dialect != SQLSERVER {
+		builder.WriteString(params.Dialect.returningClause(returning))
 	}
 
 	return builder.String()
@@ -242,8 +262,10 @@ func GetUpdateQuery(params QueryConfig) string {
 //region Select query
 
 // Возвращает строку типа SELECT ItemFieldTag1, ItemFieldTag2 ... FROM TableName [WHERE ColumnName = $1], если вы передаете ColumnName, в конец строки будет добавлено WHERE ColumnName = $1, аргумент для него должен быть первым в списке аргументов
+// Если указан Where, то условие добавляется через AND, его аргументы (WhereArgs) идут последними. В конец добавляются ORDER BY и LIMIT/OFFSET (OFFSET ... FETCH NEXT для SQLSERVER)
 // ==============================================================================================================================
 // Returns a string of type SELECT ItemFieldTag1, ItemFieldTag2 ... FROM TableName, if you pass columnName, WHERE columnName = $1 will be added to the end of the line, the argument for it must be the first in the argument list.
+// If Where is specified the condition is added with AND, its arguments (WhereArgs) go last. ORDER BY and LIMIT/OFFSET (OFFSET ... FETCH NEXT for SQLSERVER) are added to the end
 func GetSelectQuery(params QueryConfig) string {
 
 	if params.Item == nil {
@@ -252,41 +274,21 @@ func GetSelectQuery(params QueryConfig) string {
 
 	var builder strings.Builder
 	typeOfN := ConversionValToNonRefType(params.Item)
-	numOfFields := typeOfN.NumField()
+	columns := itemColumns(params, SELECT, false)
+	numOfFields := len(columns)
 
 	additionalSymbols := 11
 	totalSymbols := len(params.TableName) + len(params.ColumnName) + additionalSymbols + numOfFields*(4+2*len(params.NameWrapper))
 
 	builder.Grow(totalSymbols)
 
-	tagName := StdTagName
-
-	if len(params.TagName) > 0 {
-		tagName = params.TagName
-	}
-
 	builder.WriteString("SELECT ")
 
-	isFieldDb := false
-	isPrevFieldDb := isFieldDb
-
-	for i := range numOfFields {
-		tag := typeOfN.Field(i).Tag.Get(tagName)
-
-		isPrevFieldDb = isFieldDb
-		isFieldDb = len(tag) > 0 && !slices.Contains(params.ExcludedTags, tag)
-
-		if isFieldDb && isPrevFieldDb {
+	for i, column := range columns {
+		if i > 0 {
 			builder.WriteString(", ")
 		}
-
-		if isFieldDb {
-			if len(params.NameWrapper) > 0 {
-				tag = WrapNigger(tag, params.NameWrapper)
-			}
-			builder.WriteString(tag)
-		}
-
+		builder.WriteString(params.wrap(column))
 	}
 
 	tbname := params.TableName
@@ -295,21 +297,13 @@ func GetSelectQuery(params QueryConfig) string {
 		tbname = typeOfN.Name()
 	}
 
-	if len(params.NameWrapper) > 0 {
-		tbname = WrapNigger(tbname, params.NameWrapper)
-	}
+	tbname = params.wrap(tbname)
 
 	builder.WriteString(" FROM " + tbname)
 
-	if len(params.ColumnName) > 0 {
-		builder.WriteString(" WHERE ")
-		filterColumnName := params.ColumnName
-		if len(params.NameWrapper) > 0 {
-			filterColumnName = WrapNigger(filterColumnName, params.NameWrapper)
-		}
-		builder.WriteString(filterColumnName)
-		builder.WriteString(" = $1")
-	}
+	writeWhere(&builder, params, 1, params.whereStartIdx())
+
+	writeOrderBy(&builder, params)
 
 	return builder.String()
 }
@@ -319,27 +313,20 @@ func GetSelectQuery(params QueryConfig) string {
 // region Delete query
 
 // Возращает строку типа DELETE FROM TableName [WHERE ColumnName = $1], при указании ColumnName в конец строки добавляет WHERE ColumnName = $1
+// Если указан Where, то условие добавляется через AND, его аргументы (WhereArgs) идут последними
 func GetDeleteQuery(params QueryConfig) string {
 	tbName := params.TableName
 
 	if len(tbName) == 0 {
-		tbName = reflect.TypeOf(params.Item).Name()
+		tbName = ConversionValToNonRefType(params.Item).Name()
 	}
 
-	columnName := params.ColumnName
+	var builder strings.Builder
+	builder.WriteString("DELETE FROM " + params.wrap(tbName))
 
-	if len(params.NameWrapper) > 0 {
-		tbName = WrapNigger(tbName, params.NameWrapper)
-	}
+	writeWhere(&builder, params, 1, params.whereStartIdx())
 
-	if len(columnName) > 0 {
-		if len(params.NameWrapper) > 0 {
-			columnName = WrapNigger(columnName, params.NameWrapper)
-		}
-		return "DELETE FROM " + tbName + " WHERE " + columnName + " = $1"
-	}
-
-	return "DELETE FROM " + tbName
+	return builder.String()
 }
 
 //endregion
@@ -423,6 +410,85 @@ func (q QueryConfig) ChangeNameWrapper(wrapper string) QueryConfig {
 	return *requiredProcessing(&query, &q)
 }
 
+func (q QueryConfig) ChangeDialect(dialect Dialect) QueryConfig {
+	query := QueryConfig{
+		TableName:   q.TableName,
+		NameWrapper: q.NameWrapper,
+		ColumnName:  q.ColumnName,
+		TagName:     q.TagName,
+		Item:        q.Item,
+	}
+	query = *requiredProcessing(&query, &q)
+	query.Dialect = dialect
+	return query
+}
+
+func (q QueryConfig) ChangeOnConflict(onConflict OnConflict) QueryConfig {
+	query := QueryConfig{
+		TableName:   q.TableName,
+		NameWrapper: q.NameWrapper,
+		ColumnName:  q.ColumnName,
+		TagName:     q.TagName,
+		Item:        q.Item,
+	}
+	query = *requiredProcessing(&query, &q)
+	query.OnConflict = onConflict.clone()
+	return query
+}
+
+func (q QueryConfig) ChangeWhere(where Condition) QueryConfig {
+	query := QueryConfig{
+		TableName:   q.TableName,
+		NameWrapper: q.NameWrapper,
+		ColumnName:  q.ColumnName,
+		TagName:     q.TagName,
+		Item:        q.Item,
+	}
+	query = *requiredProcessing(&query, &q)
+	query.Where = where
+	return query
+}
+
+func (q QueryConfig) ChangeOrderBy(orders ...Order) QueryConfig {
+	query := QueryConfig{
+		TableName:   q.TableName,
+		NameWrapper: q.NameWrapper,
+		ColumnName:  q.ColumnName,
+		TagName:     q.TagName,
+		Item:        q.Item,
+	}
+	query = *requiredProcessing(&query, &q)
+	query.OrderBy = slices.Clone(orders)
+	return query
+}
+
+func (q QueryConfig) ChangeLimit(limit int, offset int) QueryConfig {
+	query := QueryConfig{
+		TableName:   q.TableName,
+		NameWrapper: q.NameWrapper,
+		ColumnName:  q.ColumnName,
+		TagName:     q.TagName,
+		Item:        q.Item,
+	}
+	query = *requiredProcessing(&query, &q)
+	query.Limit = limit
+	query.Offset = offset
+	return query
+}
+
+func (q QueryConfig) ChangeReturning(columns ...string) QueryConfig {
+	query := QueryConfig{
+		TableName:   q.TableName,
+		NameWrapper: q.NameWrapper,
+		ColumnName:  q.ColumnName,
+		TagName:     q.TagName,
+		Item:        q.Item,
+	}
+	query = *requiredProcessing(&query, &q)
+	query.Returning = slices.Clone(columns)
+	return query
+}
+
 func requiredProcessing(new *QueryConfig, old *QueryConfig) *QueryConfig {
 	var newExcTags []string
 	if len(old.ExcludedTags) > 0 {
@@ -430,6 +496,13 @@ func requiredProcessing(new *QueryConfig, old *QueryConfig) *QueryConfig {
 		copy(newExcTags, old.ExcludedTags)
 	}
 	new.ExcludedTags = newExcTags
+	new.Dialect = old.Dialect
+	new.OnConflict = old.OnConflict.clone()
+	new.Where = old.Where
+	new.OrderBy = slices.Clone(old.OrderBy)
+	new.Limit = old.Limit
+	new.Offset = old.Offset
+	new.Returning = slices.Clone(old.Returning)
 	return new
 }
 
@@ -451,6 +524,11 @@ type cacheKey struct {
 	ColumnName   string
 	NameWrapper  string
 	ExcludedTags string // отсортированная строка тегов
+	Dialect      Dialect
+	OrderBy      string
+	Limit        int
+	Offset       int
+	Returning    string
 }
 
 func GetCachedQuery(params QueryConfig) string {
@@ -464,12 +542,19 @@ func GetCachedQuery(params QueryConfig) string {
 		return GetSelectQueryCached(params)
 	case DELETE:
 		return GetDeleteQuery(params)
+	case UPSERT:
+		return GetUpsertQuery(params)
 	}
 
 	return "this query type is not supported"
 }
 
 func GetInsertQueryCached(params QueryConfig) string {
+	// столбцы omitempty зависят от значений полей
+	if hasOmitEmpty(params) {
+		return GetInsertQuery(params)
+	}
+
 	itemType := ConversionValToNonRefType(params.Item)
 
 	key := cacheKey{
@@ -479,6 +564,8 @@ func GetInsertQueryCached(params QueryConfig) string {
 		ColumnName:   params.ColumnName,
 		NameWrapper:  params.NameWrapper,
 		ExcludedTags: getExcludedTagsKey(params.ExcludedTags),
+		Dialect:      params.Dialect,
+		Returning:    strings.Join(params.Returning, ","),
 	}
 
 	cacheMutex.RLock()
@@ -498,6 +585,11 @@ func GetInsertQueryCached(params QueryConfig) string {
 }
 
 func GetUpdateQueryCached(params QueryConfig) string {
+	// условие и столбцы omitempty не входят в ключ кэша
+	if params.Where != nil || hasOmitEmpty(params) {
+		return GetUpdateQuery(params)
+	}
+
 	itemType := ConversionValToNonRefType(params.Item)
 
 	key := cacheKey{
@@ -507,6 +599,8 @@ func GetUpdateQueryCached(params QueryConfig) string {
 		ColumnName:   params.ColumnName,
 		NameWrapper:  params.NameWrapper,
 		ExcludedTags: getExcludedTagsKey(params.ExcludedTags),
+		Dialect:      params.Dialect,
+		Returning:    strings.Join(params.Returning, ","),
 	}
 
 	cacheMutex.RLock()
@@ -526,6 +620,11 @@ func GetUpdateQueryCached(params QueryConfig) string {
 }
 
 func GetSelectQueryCached(params QueryConfig) string {
+	// условие не входит в ключ кэша
+	if params.Where != nil {
+		return GetSelectQuery(params)
+	}
+
 	itemType := ConversionValToNonRefType(params.Item)
 
 	key := cacheKey{
@@ -535,6 +634,10 @@ func GetSelectQueryCached(params QueryConfig) string {
 		ColumnName:   params.ColumnName,
 		NameWrapper:  params.NameWrapper,
 		ExcludedTags: getExcludedTagsKey(params.ExcludedTags),
+		Dialect:      params.Dialect,
+		OrderBy:      getOrderByKey(params.OrderBy),
+		Limit:        params.Limit,
+		Offset:       params.Offset,
 	}
 
 	cacheMutex.RLock()
@@ -578,10 +681,39 @@ func ConversionValToNonRefType(value any) reflect.Type {
 
 //region Join
 
+// Вид JOIN, FULLJOIN не поддерживается MYSQL / Kind of JOIN, FULLJOIN is not supported by MYSQL
+type JoinKind int
+
+const (
+	INNERJOIN JoinKind = iota
+	LEFTJOIN
+	RIGHTJOIN
+	FULLJOIN
+)
+
+func (k JoinKind) String() string {
+	switch k {
+	case LEFTJOIN:
+		return "LEFT JOIN"
+	case RIGHTJOIN:
+		return "RIGHT JOIN"
+	case FULLJOIN:
+		return "FULL JOIN"
+	}
+	return "JOIN"
+}
+
+// Построитель SELECT ... FROM ... JOIN ..., аргументы условий ON и WHERE нумеруются в порядке появления в строке запроса
+// ======================================================================================
+// Builder of SELECT ... FROM ... JOIN ..., arguments of the ON and WHERE conditions are numbered in the order they appear in the query string
 type JoinQuery struct {
 	queryConfig       *QueryConfig
 	builder           *strings.Builder
+	from              string
+	fromAlias         string
+	joins             strings.Builder
 	previousTableName string
+	args              []any
 }
 
 type TC struct {
@@ -624,43 +756,177 @@ func (q QueryConfig) StartJoin(startTableName string, pairs ...TC) *JoinQuery {
 			additionalStr = ", "
 		}
 
-		builder.WriteString(WrapNigger(val.TableName, q.NameWrapper) + "." + WrapNigger(val.ColumnName, q.NameWrapper) + additionalStr)
+		builder.WriteString(q.wrap(val.TableName) + "." + q.wrap(val.ColumnName) + additionalStr)
+	}
+
+	return q.startJoin(&builder, startTableName)
+}
+
+// Начинает JOIN, список столбцов строится по полям item с тегом join: для struct{ User `join:"Users"`; Role `join:"Roles"` }
+// будет сгенерировано SELECT "Users"."Id" AS "Users__Id", ..., "Roles"."Id" AS "Roles__Id" ... FROM "Users"
+// Результат можно сканировать в срез таких структур, столбцы попадают в поля вложенных моделей по псевдонимам
+// Значение тега join может быть псевдонимом таблицы, см. As и JoinOn. Псевдонимы столбцов экранируются даже без NameWrapper
+// ======================================================================================
+// Starts JOIN, the column list is built from the item fields with the join tag: for struct{ User `join:"Users"`; Role `join:"Roles"` }
+// SELECT "Users"."Id" AS "Users__Id", ..., "Roles"."Id" AS "Roles__Id" ... FROM "Users" will be generated
+// The result can be scanned into a slice of such structs, columns get into the fields of the nested models by aliases
+// The value of the join tag can be a table alias, see As and JoinOn. Column aliases are quoted even without NameWrapper
+func (q QueryConfig) StartJoinInto(item any, startTableName string) *JoinQuery {
+	tagName := StdTagName
+
+	if len(q.TagName) > 0 {
+		tagName = q.TagName
+	}
+
+	var builder strings.Builder
+	builder.WriteString("SELECT ")
+
+	idx := 0
+	for _, field := range StructFields(reflect.TypeOf(item), tagName) {
+		if len(field.Table) == 0 || slices.Contains(q.ExcludedTags, field.Column) {
+			continue
+		}
+
+		if idx > 0 {
+			builder.WriteString(", ")
+		}
+		idx++
+
+		// псевдоним экранируется всегда, иначе POSTGRES приведет его к нижнему регистру
+		alias := q.wrap(field.Column)
+		if alias == field.Column {
+			alias = q.Dialect.Quote(field.Column)
+		}
+
+		builder.WriteString(q.wrap(field.Table) + "." + q.wrap(field.Name) + " AS " + alias)
 	}
 
-	wrapped := WrapNigger(startTableName, q.NameWrapper)
+	q.Item = item
 
-	builder.WriteString(" FROM " + wrapped)
+	return q.startJoin(&builder, startTableName)
+}
+
+func (q QueryConfig) startJoin(builder *strings.Builder, startTableName string) *JoinQuery {
+	wrapped := q.wrap(startTableName)
 
 	return &JoinQuery{
 		queryConfig:       &q,
-		builder:           &builder,
+		builder:           builder,
+		from:              wrapped,
 		previousTableName: wrapped,
 	}
 }
 
+// Задает псевдоним начальной таблицы: FROM "Users" AS "u", если соединений еще не было, то Join продолжит цепочку от псевдонима
+// ======================================================================================
+// Sets the alias of the start table: FROM "Users" AS "u", if there were no joins yet Join continues the chain from the alias
+func (j *JoinQuery) As(alias string) *JoinQuery {
+	j.fromAlias = j.queryConfig.wrap(alias)
+	if j.joins.Len() == 0 {
+		j.previousTableName = j.fromAlias
+	}
+	return j
+}
+
+// Соединяет предыдущую таблицу цепочки с новой по равенству столбцов: JOIN new ON prev.col = new.col
+// ======================================================================================
+// Joins the previous table of the chain with the new one by column equality: JOIN new ON prev.col = new.col
 func (j *JoinQuery) Join(previousTableColumnName string, newJoinedTable TC) *JoinQuery {
 	growCount := 15 + len(previousTableColumnName) + len(newJoinedTable.TableName) + len(newJoinedTable.ColumnName) + len(j.previousTableName)
-	j.builder.Grow(growCount)
-	wrapped := WrapNigger(newJoinedTable.TableName, j.queryConfig.NameWrapper)
-	j.builder.WriteString(" JOIN " + wrapped + " ON " + j.previousTableName + "." +
-		WrapNigger(previousTableColumnName, j.queryConfig.NameWrapper) + " = " + wrapped + "." + WrapNigger(newJoinedTable.ColumnName, j.queryConfig.NameWrapper))
+	j.joins.Grow(growCount)
+	wrapped := j.queryConfig.wrap(newJoinedTable.TableName)
+	j.joins.WriteString(" JOIN " + wrapped + " ON " + j.previousTableName + "." +
+		j.queryConfig.wrap(previousTableColumnName) + " = " + wrapped + "." + j.queryConfig.wrap(newJoinedTable.ColumnName))
 	j.previousTableName = wrapped
 	return j
 }
 
+// Добавляет kind JOIN table [AS alias] ON on, условие может ссылаться на любую ранее присоединенную таблицу или псевдоним
+// Для сравнения столбцов используйте EqCol и подобные, значения в условии становятся аргументами
+// ======================================================================================
+// Adds kind JOIN table [AS alias] ON on, the condition can refer to any previously joined table or alias
+// Use EqCol and the like to compare columns, values in the condition become arguments
+func (j *JoinQuery) JoinOn(kind JoinKind, table string, alias string, on Condition) *JoinQuery {
+	wrapped := j.queryConfig.wrap(table)
+	j.joins.WriteString(" " + kind.String() + " " + wrapped)
+
+	if len(alias) > 0 {
+		wrapped = j.queryConfig.wrap(alias)
+		j.joins.WriteString(" AS " + wrapped)
+	}
+
+	if on != nil {
+		condition, args := BuildCondition(*j.queryConfig, on, len(j.args)+1)
+		j.joins.WriteString(" ON " + condition)
+		j.args = append(j.args, args...)
+	}
+
+	j.previousTableName = wrapped
+	return j
+}
+
+func (j *JoinQuery) InnerJoin(table string, alias string, on Condition) *JoinQuery {
+	return j.JoinOn(INNERJOIN, table, alias, on)
+}
+
+func (j *JoinQuery) LeftJoin(table string, alias string, on Condition) *JoinQuery {
+	return j.JoinOn(LEFTJOIN, table, alias, on)
+}
+
+func (j *JoinQuery) RightJoin(table string, alias string, on Condition) *JoinQuery {
+	return j.JoinOn(RIGHTJOIN, table, alias, on)
+}
+
+func (j *JoinQuery) FullJoin(table string, alias string, on Condition) *JoinQuery {
+	return j.JoinOn(FULLJOIN, table, alias, on)
+}
+
+func (j *JoinQuery) query() string {
+	from := j.from
+	if len(j.fromAlias) > 0 {
+		from += " AS " + j.fromAlias
+	}
+	return j.builder.String() + " FROM " + from + j.joins.String()
+}
+
+// Возвращает строку запроса с условиями table.column = $n, объединенными через AND, аргументы для них идут после аргументов ON
+// ======================================================================================
+// Returns the query string with the table.column = $n conditions combined with AND, arguments for them go after the ON arguments
 func (j *JoinQuery) Result(pairs ...TC) string {
-	growCount := j.builder.Len() + len(pairs)*8
+	query := j.query()
+
+	if len(pairs) == 0 {
+		return query
+	}
+
+	growCount := len(query) + len(pairs)*8
 	var newBuilder strings.Builder
 	newBuilder.Grow(growCount)
-	newBuilder.WriteString(j.builder.String() + " WHERE ")
+	newBuilder.WriteString(query + " WHERE ")
 	for idx, val := range pairs {
 		additionalStr := ""
 		if idx != len(pairs)-1 {
-			additionalStr = ", "
+			additionalStr = " AND "
 		}
-		newBuilder.WriteString(WrapNigger(val.TableName, j.queryConfig.NameWrapper) + "." + WrapNigger(val.ColumnName, j.queryConfig.NameWrapper) + "=$" + strconv.Itoa(idx+1) + additionalStr)
+		newBuilder.WriteString(j.queryConfig.wrap(val.TableName) + "." + j.queryConfig.wrap(val.ColumnName) + "=" + j.queryConfig.placeholder(len(j.args)+idx+1) + additionalStr)
 	}
 	return newBuilder.String()
 }
 
+// Возвращает строку запроса с условием WHERE (если where не nil) и все аргументы: сначала аргументы ON, затем WHERE
+// ======================================================================================
+// Returns the query string with the WHERE condition (if where is not nil) and all arguments: ON arguments first, then WHERE ones
+func (j *JoinQuery) Where(where Condition) (string, []any) {
+	query := j.query()
+	args := slices.Clone(j.args)
+
+	if where == nil {
+		return query, args
+	}
+
+	condition, whereArgs := BuildCondition(*j.queryConfig, where, len(args)+1)
+
+	return query + " WHERE " + condition, append(args, whereArgs...)
+}
+
 //endregion
@@ -269,3 +269,150 @@ func TestJoin(t *testing.T) {
 	}
 
 }
+
+type joinUser struct {
+	Id   int    `db:"Id"`
+	Name string `db:"Name"`
+}
+
+type joinRole struct {
+	Id    int    `db:"Id"`
+	Title string `db:"Title"`
+}
+
+type userWithRole struct {
+	joinUser `join:"Users"`
+	Role     joinRole `join:"Roles"`
+	Ignored  int      `db:"Ignored"`
+}
+
+func TestJoinInto(t *testing.T) {
+	query := "SELECT \"Users\".\"Id\" AS \"Users__Id\", \"Users\".\"Name\" AS \"Users__Name\", \"Roles\".\"Id\" AS \"Roles__Id\", \"Roles\".\"Title\" AS \"Roles__Title\" FROM \"Users\" " +
+		"JOIN \"Roles\" ON \"Users\".\"RoleId\" = \"Roles\".\"Id\""
+
+	genQuery := QueryConfig{NameWrapper: wrapper, TagName: "db"}.StartJoinInto(userWithRole{}, "Users").Join("RoleId", TCC("Roles", "Id")).Result()
+
+	if query != genQuery {
+		t.Errorf("queries don`t match %s", query+" <- OG \n"+genQuery+" <- FAKE")
+	}
+}
+
+func TestJoinBuilder(t *testing.T) {
+	q := QueryConfig{NameWrapper: wrapper}
+
+	query, args := q.StartJoin("Users", TCC("u", "Name"), TCC("m", "Name"), TCC("r", "Title")).As("u").
+		LeftJoin("Users", "m", EqCol("u.ManagerId", "m.Id")).
+		JoinOn(INNERJOIN, "Roles", "r", And(EqCol("r.Id", "u.RoleId"), Eq("r.Active", true))).
+		Where(And(Eq("u.Id", 5), Or(IsNull("m.Id"), Gt("m.Level", 2))))
+
+	expected := "SELECT \"u\".\"Name\", \"m\".\"Name\", \"r\".\"Title\" FROM \"Users\" AS \"u\" " +
+		"LEFT JOIN \"Users\" AS \"m\" ON \"u\".\"ManagerId\" = \"m\".\"Id\" " +
+		"JOIN \"Roles\" AS \"r\" ON \"r\".\"Id\" = \"u\".\"RoleId\" AND \"r\".\"Active\" = $1 " +
+		"WHERE \"u\".\"Id\" = $2 AND (\"m\".\"Id\" IS NULL OR \"m\".\"Level\" > $3)"
+
+	if query != expected {
+		t.Errorf("queries don`t match %s", expected+" <- OG \n"+query+" <- FAKE")
+	}
+
+	if len(args) != 3 || args[0] != true || args[1] != 5 || args[2] != 2 {
+		t.Errorf("args don`t match %v", args)
+	}
+
+	result := q.StartJoin("Users", TCC("Users", "Id")).RightJoin("Roles", "", EqCol("Users.RoleId", "Roles.Id")).Result(TCC("Users", "Id"), TCC("Roles", "Id"))
+	expected = "SELECT \"Users\".\"Id\" FROM \"Users\" RIGHT JOIN \"Roles\" ON \"Users\".\"RoleId\" = \"Roles\".\"Id\" WHERE \"Users\".\"Id\"=$1 AND \"Roles\".\"Id\"=$2"
+
+	if result != expected {
+		t.Errorf("queries don`t match %s", expected+" <- OG \n"+result+" <- FAKE")
+	}
+}
+
+func TestDialects(t *testing.T) {
+	query := QueryConfig{
+		TableName:    tableName,
+		NameWrapper:  wrapper,
+		ColumnName:   columnName,
+		TagName:      "",
+		Item:         user2{},
+		ExcludedTags: []string{"Id"},
+	}
+
+	cases := []struct {
+		name     string
+		res      string
+		expected string
+	}{
+		{"mysql insert", GetInsertQuery(query.ChangeDialect(MYSQL)), "INSERT INTO `users` (`Name`, `Password`, `Description`) VALUES (?,?,?)"},
+		{"mysql update", GetUpdateQuery(query.ChangeDialect(MYSQL)), "UPDATE `users` SET `Name` = ?, `Password` = ?, `Description` = ? WHERE `Id` = ?"},
+		{"mysql select", GetSelectQuery(query.ChangeDialect(MYSQL)), "SELECT `Name`, `Password`, `Description` FROM `users` WHERE `Id` = ?"},
+		{"mysql delete", GetDeleteQuery(query.ChangeDialect(MYSQL)), "DELETE FROM `users` WHERE `Id` = ?"},
+		{"sqlite insert", GetInsertQuery(query.ChangeDialect(SQLITE)), "INSERT INTO \"users\" (\"Name\", \"Password\", \"Description\") VALUES (?1,?2,?3) RETURNING \"Id\""},
+		{"sqlite update", GetUpdateQuery(query.ChangeDialect(SQLITE)), "UPDATE \"users\" SET \"Name\" = ?2, \"Password\" = ?3, \"Description\" = ?4 WHERE \"Id\" = ?1"},
+		{"sqlserver insert", GetInsertQuery(query.ChangeDialect(SQLSERVER)), "INSERT INTO [users] ([Name], [Password], [Description]) OUTPUT INSERTED.[Id] VALUES (@p1,@p2,@p3)"},
+		{"sqlserver select", GetSelectQuery(query.ChangeDialect(SQLSERVER)), "SELECT [Name], [Password], [Description] FROM [users] WHERE [Id] = @p1"},
+		{"mysql without wrapper", GetDeleteQuery(query.ChangeDialect(MYSQL).ChangeNameWrapper("")), "DELETE FROM `users` WHERE `Id` = ?"},
+		{"postgres without wrapper", GetInsertQuery(query.ChangeDialect(POSTGRES).ChangeNameWrapper("")), "INSERT INTO \"users\" (\"Name\", \"Password\", \"Description\") VALUES ($1,$2,$3) RETURNING \"Id\""},
+	}
+
+	for _, c := range cases {
+		if c.res != c.expected {
+			t.Errorf("%s: QUERIES NOT MATCH\n%s\n%s", c.name, c.expected, c.res)
+		}
+	}
+
+	if GetInsertQueryCached(query) == GetInsertQueryCached(query.ChangeDialect(MYSQL)) {
+		t.Errorf("cache must distinguish dialects")
+	}
+}
+
+func TestInsertManyQuery(t *testing.T) {
+	query := QueryConfig{
+		TableName:    tableName,
+		Item:         user2{},
+		ColumnName:   columnName,
+		ExcludedTags: []string{"Id", "Description"},
+	}
+
+	expected := "INSERT INTO users (Name, Password) VALUES ($1,$2),($3,$4),($5,$6) RETURNING Id"
+	if res := GetInsertManyQuery(query, 3); res != expected {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", expected, res)
+	}
+
+	expected = "INSERT INTO [users] ([Name], [Password]) OUTPUT INSERTED.[Id] VALUES (@p1,@p2),(@p3,@p4)"
+	if res := GetInsertManyQuery(query.ChangeDialect(SQLSERVER), 2); res != expected {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", expected, res)
+	}
+}
+
+func TestReturningQuery(t *testing.T) {
+	query := QueryConfig{
+		TableName:    tableName,
+		Item:         user2{},
+		ColumnName:   columnName,
+		ExcludedTags: []string{"Id", "Description"},
+		Dialect:      POSTGRES,
+		Returning:    []string{"Id", "CreatedAt"},
+	}
+
+	cases := []struct {
+		name     string
+		res      string
+		expected string
+	}{
+		{"postgres insert", GetInsertQuery(query), "INSERT INTO \"users\" (\"Name\", \"Password\") VALUES ($1,$2) RETURNING \"Id\", \"CreatedAt\""},
+		{"postgres update", GetUpdateQuery(query.ChangeReturning("Version")), "UPDATE \"users\" SET \"Name\" = $2, \"Password\" = $3 WHERE \"Id\" = $1 RETURNING \"Version\""},
+		{"sqlserver insert", GetInsertQuery(query.ChangeDialect(SQLSERVER)), "INSERT INTO [users] ([Name], [Password]) OUTPUT INSERTED.[Id], INSERTED.[CreatedAt] VALUES (@p1,@p2)"},
+		{"sqlserver update", GetUpdateQuery(query.ChangeDialect(SQLSERVER)), "UPDATE [users] SET [Name] = @p2, [Password] = @p3 OUTPUT INSERTED.[Id], INSERTED.[CreatedAt] WHERE [Id] = @p1"},
+		{"mysql update", GetUpdateQuery(query.ChangeDialect(MYSQL)), "UPDATE `users` SET `Name` = ?, `Password` = ? WHERE `Id` = ?"},
+		{"update without returning", GetUpdateQuery(query.ChangeReturning()), "UPDATE \"users\" SET \"Name\" = $2, \"Password\" = $3 WHERE \"Id\" = $1"},
+	}
+
+	for _, c := range cases {
+		if c.res != c.expected {
+			t.Errorf("%s: QUERIES NOT MATCH\n%s\n%s", c.name, c.expected, c.res)
+		}
+	}
+
+	if GetUpdateQueryCached(query) == GetUpdateQueryCached(query.ChangeReturning()) {
+		t.Errorf("cache must distinguish returning columns")
+	}
+}
@@ -0,0 +1,329 @@
+package sqlstrings
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// Тег поля составной структуры, значение - имя таблицы, из которой читается вложенная модель
+	// ======================================================================================
+	// Tag of a composite struct field, the value is the name of the table the nested model is read from
+	JoinTagName = "join"
+	// Разделитель имени таблицы и столбца в псевдонимах: Users__Id / Separator of the table and column names in aliases: Users__Id
+	JoinSeparator = "__"
+)
+
+// Опции тега поля: db:"Id,pk,auto"
+// pk - первичный ключ, не обновляется в UPDATE ;
+// auto - значение генерирует база (автоинкремент), столбец не записывается и возвращается через RETURNING если ColumnName не указан ;
+// readonly - столбец только читается ;
+// insert - столбец записывается только в INSERT ;
+// update - столбец записывается только в UPDATE ;
+// omitempty - нулевое значение не записывается (кроме INSERT нескольких записей) ;
+// secret - значение скрывается в логах ;
+// ======================================================================================
+// Field tag options: db:"Id,pk,auto"
+// pk - primary key, it is not updated by UPDATE ;
+// auto - the value is generated by the database (autoincrement), the column is not written and is returned via RETURNING if ColumnName is not specified ;
+// readonly - the column is only read ;
+// insert - the column is written only by INSERT ;
+// update - the column is written only by UPDATE ;
+// omitempty - a zero value is not written (except for INSERT of several records) ;
+// secret - the value is hidden in logs ;
+const (
+	PKOption        = "pk"
+	AutoOption      = "auto"
+	ReadOnlyOption  = "readonly"
+	InsertOption    = "insert"
+	UpdateOption    = "update"
+	OmitEmptyOption = "omitempty"
+	SecretOption    = "secret"
+)
+
+// Разобранный тег поля вида "name,option1,option2", тег "-" означает что поле пропускается
+// ======================================================================================
+// Parsed field tag of the "name,option1,option2" form, the "-" tag means that the field is skipped
+type Tag struct {
+	Name    string
+	Options []string
+}
+
+func ParseTag(tag string) Tag {
+	parts := strings.Split(tag, ",")
+
+	res := Tag{Name: strings.TrimSpace(parts[0])}
+	for _, option := range parts[1:] {
+		if option = strings.TrimSpace(option); len(option) > 0 {
+			res.Options = append(res.Options, option)
+		}
+	}
+
+	return res
+}
+
+func (t Tag) HasOption(option string) bool {
+	return slices.Contains(t.Options, option)
+}
+
+// Записывается ли столбец запросом типа queryType, SELECT и DELETE читают все столбцы
+// ======================================================================================
+// Reports whether the column is written by a query of the queryType type, SELECT and DELETE read all columns
+func (t Tag) Writable(queryType QueryType) bool {
+	switch queryType {
+	case INSERT, UPSERT:
+		return !t.HasOption(AutoOption) && !t.HasOption(ReadOnlyOption) && !t.HasOption(UpdateOption)
+	case UPDATE:
+		return !t.HasOption(AutoOption) && !t.HasOption(ReadOnlyOption) && !t.HasOption(InsertOption) && !t.HasOption(PKOption)
+	}
+	return true
+}
+
+// Column - полное имя столбца с учетом префиксов вложенных структур, для полей моделей с тегом join это псевдоним Table__Name ;
+// Table - таблица из тега join, пустая для обычных полей ;
+// Name - имя столбца в таблице Table ;
+// Index - путь к полю для reflect.Value.FieldByIndex ;
+// Field - поле структуры ;
+// Tag - разобранный тег поля ;
+// ======================================================================================
+// Column - full column name including the prefixes of nested structs, for fields of models with the join tag it is the Table__Name alias ;
+// Table - table from the join tag, empty for regular fields ;
+// Name - column name in the Table table ;
+// Index - path to the field for reflect.Value.FieldByIndex ;
+// Field - struct field ;
+// Tag - parsed field tag ;
+type StructField struct {
+	Column string
+	Table  string
+	Name   string
+	Index  []int
+	Field  reflect.StructField
+	Tag    Tag
+}
+
+//region Struct fields
+
+// Возвращает поля структуры t, соответствующие столбцам, в порядке объявления
+// Встроенные структуры без тега разворачиваются, именованные вложенные структуры с опцией prefix (db:"addr_,prefix") разворачиваются с префиксом имени столбца
+// Структуры с тегом join (join:"Users") разворачиваются с префиксом Users__, см. StartJoinInto
+// Встроенные указатели на структуры не разворачиваются
+// ======================================================================================
+// Returns the fields of the t struct that correspond to columns, in declaration order
+// Embedded structs without a tag are flattened, named nested structs with the prefix option (db:"addr_,prefix") are flattened with the column name prefix
+// Structs with the join tag (join:"Users") are flattened with the Users__ prefix, see StartJoinInto
+// Embedded pointers to structs are not flattened
+func StructFields(t reflect.Type, tagName string) []StructField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return appendStructFields(nil, t, tagName, "", "", nil)
+}
+
+func appendStructFields(fields []StructField, t reflect.Type, tagName string, table string, prefix string, index []int) []StructField {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := ParseTag(field.Tag.Get(tagName))
+
+		// поля-связи (тег rel) заполняются отдельными запросами
+		if _, ok := field.Tag.Lookup("rel"); tag.Name == "-" || ok {
+			continue
+		}
+
+		fieldIndex := append(slices.Clone(index), i)
+
+		if join := field.Tag.Get(JoinTagName); len(join) > 0 && len(table) == 0 {
+			if field.Type.Kind() == reflect.Struct && (field.IsExported() || field.Anonymous) {
+				fields = appendStructFields(fields, field.Type, tagName, join, "", fieldIndex)
+			}
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && len(tag.Name) == 0 && !tag.HasOption("prefix") {
+			fields = appendStructFields(fields, field.Type, tagName, table, prefix, fieldIndex)
+			continue
+		}
+
+		if tag.HasOption("prefix") {
+			if field.Type.Kind() == reflect.Struct && field.IsExported() {
+				fields = appendStructFields(fields, field.Type, tagName, table, prefix+tag.Name, fieldIndex)
+			}
+			continue
+		}
+
+		if len(tag.Name) == 0 {
+			continue
+		}
+
+		column := prefix + tag.Name
+		if len(table) > 0 {
+			column = table + JoinSeparator + column
+		}
+
+		fields = append(fields, StructField{
+			Column: column,
+			Table:  table,
+			Name:   prefix + tag.Name,
+			Index:  fieldIndex,
+			Field:  field,
+			Tag:    tag,
+		})
+	}
+
+	return fields
+}
+
+// Возвращает поля params.Item, участвующие в запросе типа queryType: без ExcludedTags и без столбцов, которые этот запрос не записывает (см. Tag.Writable)
+// При omitEmpty пропускаются поля с опцией omitempty и нулевым значением в params.Item
+// ======================================================================================
+// Returns the fields of params.Item that take part in a query of the queryType type: without ExcludedTags and without the columns this query does not write (see Tag.Writable)
+// With omitEmpty the fields with the omitempty option and a zero value in params.Item are skipped
+func QueryFields(params QueryConfig, queryType QueryType, omitEmpty bool) []StructField {
+	tagName := StdTagName
+
+	if len(params.TagName) > 0 {
+		tagName = params.TagName
+	}
+
+	if params.Item == nil {
+		return nil
+	}
+
+	item := reflect.ValueOf(params.Item)
+	for item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			break
+		}
+		item = item.Elem()
+	}
+
+	var fields []StructField
+	for _, field := range StructFields(reflect.TypeOf(params.Item), tagName) {
+		if slices.Contains(params.ExcludedTags, field.Column) || !field.Tag.Writable(queryType) {
+			continue
+		}
+
+		if omitEmpty && queryType != SELECT && queryType != DELETE && field.Tag.HasOption(OmitEmptyOption) &&
+			item.Kind() == reflect.Struct && item.FieldByIndex(field.Index).IsZero() {
+			continue
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// Возвращает имена столбцов QueryFields
+// ======================================================================================
+// Returns the column names of QueryFields
+func itemColumns(params QueryConfig, queryType QueryType, omitEmpty bool) []string {
+	fields := QueryFields(params, queryType, omitEmpty)
+
+	columns := make([]string, 0, len(fields))
+	for _, field := range fields {
+		columns = append(columns, field.Column)
+	}
+	return columns
+}
+
+// Есть ли у типа params.Item поля с опцией omitempty, такие запросы зависят от значений и не кэшируются
+// ======================================================================================
+// Reports whether the params.Item type has fields with the omitempty option, such queries depend on values and are not cached
+func hasOmitEmpty(params QueryConfig) bool {
+	key := omitEmptyKey{Type: reflect.TypeOf(params.Item), TagName: params.TagName}
+
+	if res, ok := omitEmptyTypes.Load(key); ok {
+		return res.(bool)
+	}
+
+	tagName := StdTagName
+	if len(params.TagName) > 0 {
+		tagName = params.TagName
+	}
+
+	res := slices.ContainsFunc(StructFields(key.Type, tagName), func(field StructField) bool {
+		return field.Tag.HasOption(OmitEmptyOption)
+	})
+	omitEmptyTypes.Store(key, res)
+
+	return res
+}
+
+type omitEmptyKey struct {
+	Type    reflect.Type
+	TagName string
+}
+
+var omitEmptyTypes sync.Map
+
+// Возвращает столбец для RETURNING: ColumnName, а если он не указан - первый столбец с опцией auto
+// ======================================================================================
+// Returns the column for RETURNING: ColumnName, and if it is not specified the first column with the auto option
+func (q QueryConfig) ReturningColumn() string {
+	if len(q.ColumnName) > 0 || q.Item == nil {
+		return q.ColumnName
+	}
+
+	tagName := StdTagName
+	if len(q.TagName) > 0 {
+		tagName = q.TagName
+	}
+
+	for _, field := range StructFields(reflect.TypeOf(q.Item), tagName) {
+		if field.Tag.HasOption(AutoOption) && !slices.Contains(q.ExcludedTags, field.Column) {
+			return field.Column
+		}
+	}
+
+	return ""
+}
+
+// Возвращает столбцы, которые возвращает INSERT: Returning, а если он не указан - ReturningColumn
+// ======================================================================================
+// Returns the columns returned by INSERT: Returning, and if it is not specified ReturningColumn
+func (q QueryConfig) ReturningColumns() []string {
+	if len(q.Returning) > 0 {
+		return q.Returning
+	}
+	if column := q.ReturningColumn(); len(column) > 0 {
+		return []string{column}
+	}
+	return nil
+}
+
+func (q QueryConfig) wrapReturning() []string {
+	var returning []string
+	for _, column := range q.ReturningColumns() {
+		returning = append(returning, q.wrap(column))
+	}
+	return returning
+}
+
+// Возвращает все имена столбцов params.Item, включая исключенные
+// ======================================================================================
+// Returns all column names of params.Item, including the excluded ones
+func itemTags(params QueryConfig) []string {
+	tagName := StdTagName
+
+	if len(params.TagName) > 0 {
+		tagName = params.TagName
+	}
+
+	fields := StructFields(reflect.TypeOf(params.Item), tagName)
+
+	tags := make([]string, 0, len(fields))
+	for _, field := range fields {
+		tags = append(tags, field.Column)
+	}
+
+	return tags
+}
+
+//endregion
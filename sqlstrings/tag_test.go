@@ -0,0 +1,124 @@
+package sqlstrings
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type audit struct {
+	CreatedAt time.Time `db:"CreatedAt"`
+	UpdatedAt time.Time `db:"UpdatedAt"`
+}
+
+type BaseEntity struct {
+	Id int `db:"Id"`
+}
+
+type address struct {
+	City   string `db:"city"`
+	Street string `db:"street"`
+}
+
+type entity struct {
+	BaseEntity
+	Name    string `db:"Name"`
+	Skipped string `db:"-"`
+	Roles   []int32
+	Address address `db:"addr_,prefix"`
+	audit
+}
+
+func TestStructFields(t *testing.T) {
+	fields := StructFields(reflect.TypeFor[*entity](), "db")
+
+	var columns []string
+	for _, field := range fields {
+		columns = append(columns, field.Column)
+	}
+
+	expected := []string{"Id", "Name", "addr_city", "addr_street", "CreatedAt", "UpdatedAt"}
+	if !slices.Equal(columns, expected) {
+		t.Errorf("columns not match %v", columns)
+	}
+
+	if !slices.Equal(fields[3].Index, []int{4, 1}) || !slices.Equal(fields[5].Index, []int{5, 1}) {
+		t.Errorf("index paths not match %v %v", fields[3].Index, fields[5].Index)
+	}
+
+	query := QueryConfig{TableName: "entities", TagName: "db", Item: entity{}, ExcludedTags: []string{"Id"}}
+
+	insert := "INSERT INTO entities (Name, addr_city, addr_street, CreatedAt, UpdatedAt) VALUES ($1,$2,$3,$4,$5)"
+	if res := GetInsertQuery(query); res != insert {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", insert, res)
+	}
+
+	// поле без тега между полями с тегами не должно ломать запятые
+	sel := "SELECT Id, Name, addr_city, addr_street, CreatedAt, UpdatedAt FROM entities"
+	if res := GetSelectQuery(query.ChangeExcludedTags()); res != sel {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", sel, res)
+	}
+
+	tag := ParseTag(" addr_ , prefix,")
+	if tag.Name != "addr_" || !tag.HasOption("prefix") || len(tag.Options) != 1 {
+		t.Errorf("ParseTag failed %#v", tag)
+	}
+}
+
+type account struct {
+	Id        int       `db:"Id,pk,auto"`
+	Login     string    `db:"Login,insert"`
+	Name      string    `db:"Name"`
+	Nick      *string   `db:"Nick,omitempty"`
+	UpdatedAt time.Time `db:"UpdatedAt,update"`
+	Version   int       `db:"Version,readonly"`
+}
+
+func TestTagOptions(t *testing.T) {
+	tag := ParseTag("Id, pk ,auto")
+	if tag.Name != "Id" || !tag.HasOption(PKOption) || !tag.HasOption(AutoOption) {
+		t.Errorf("tag not parsed %+v", tag)
+	}
+
+	nick := "nick"
+	query := QueryConfig{TableName: "accounts", TagName: "db", Item: account{Nick: &nick}, Dialect: POSTGRES}
+
+	insert := "INSERT INTO \"accounts\" (\"Login\", \"Name\", \"Nick\") VALUES ($1,$2,$3) RETURNING \"Id\""
+	if res := GetInsertQuery(query); res != insert {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", insert, res)
+	}
+
+	// нулевое значение omitempty не записывается, кэш не должен вернуть запрос с Nick
+	insert = "INSERT INTO \"accounts\" (\"Login\", \"Name\") VALUES ($1,$2) RETURNING \"Id\""
+	GetInsertQueryCached(query)
+	if res := GetInsertQueryCached(query.ChangeItem(account{})); res != insert {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", insert, res)
+	}
+
+	// у нескольких записей omitempty не учитывается
+	insertMany := "INSERT INTO \"accounts\" (\"Login\", \"Name\", \"Nick\") VALUES ($1,$2,$3),($4,$5,$6) RETURNING \"Id\""
+	if res := GetInsertManyQuery(query.ChangeItem(account{}), 2); res != insertMany {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", insertMany, res)
+	}
+
+	update := "UPDATE \"accounts\" SET \"Name\" = $2, \"Nick\" = $3, \"UpdatedAt\" = $4 WHERE \"Id\" = $1"
+	if res := GetUpdateQuery(query.ChangeColumnName("Id")); res != update {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", update, res)
+	}
+
+	sel := "SELECT \"Id\", \"Login\", \"Name\", \"Nick\", \"UpdatedAt\", \"Version\" FROM \"accounts\""
+	if res := GetSelectQuery(query); res != sel {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", sel, res)
+	}
+
+	upsert := "INSERT INTO \"accounts\" (\"Login\", \"Name\", \"Nick\") VALUES ($1,$2,$3) ON CONFLICT (\"Login\") DO UPDATE SET \"Name\" = EXCLUDED.\"Name\", \"Nick\" = EXCLUDED.\"Nick\" RETURNING \"Id\""
+	if res := GetUpsertQuery(query.ChangeOnConflict(OnConflict{Columns: []string{"Login"}})); res != upsert {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", upsert, res)
+	}
+
+	// явно указанный ColumnName важнее опции auto
+	if res := query.ChangeColumnName("Login").ReturningColumn(); res != "Login" {
+		t.Errorf("returning column not match %s", res)
+	}
+}
@@ -0,0 +1,204 @@
+package sqlstrings
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Columns - столбцы конфликта (уникальный ключ), для MYSQL не используются, т.к. там конфликт определяется любым уникальным ключом ;
+// DoNothing - при конфликте ничего не делать ;
+// UpdateColumns - столбцы которые обновляются при конфликте, если не указаны то обновляются все столбцы вставки кроме столбцов конфликта ;
+// =========================================================================================================================================================
+// Columns - conflict columns (unique key), they are not used for MYSQL because there the conflict is detected by any unique key ;
+// DoNothing - do nothing on conflict ;
+// UpdateColumns - columns that are updated on conflict, if not specified all inserted columns except the conflict columns are updated ;
+type OnConflict struct {
+	Columns       []string
+	DoNothing     bool
+	UpdateColumns []string
+}
+
+func (o OnConflict) clone() OnConflict {
+	return OnConflict{
+		Columns:       slices.Clone(o.Columns),
+		DoNothing:     o.DoNothing,
+		UpdateColumns: slices.Clone(o.UpdateColumns),
+	}
+}
+
+//region Upsert query
+
+// Для обновления при конфликте не указаны столбцы конфликта / Conflict columns are not specified for the update on conflict
+var ErrNoConflictColumns = errors.New("OnConflict.Columns must be specified to update on conflict")
+
+// Проверяет что для обновления при конфликте указаны столбцы конфликта, POSTGRES и SQLITE не принимают ON CONFLICT DO UPDATE без них,
+// а MERGE без условия совпадения никогда не обновляет. Для MYSQL и DoNothing столбцы не обязательны
+// ======================================================================================
+// Checks that the conflict columns are specified for the update on conflict, POSTGRES and SQLITE do not accept ON CONFLICT DO UPDATE without them,
+// and MERGE without the match condition never updates. The columns are optional for MYSQL and DoNothing
+func ValidateOnConflict(params QueryConfig) error {
+	if params.Dialect == MYSQL || params.OnConflict.DoNothing || len(params.OnConflict.Columns) > 0 {
+		return nil
+	}
+	return ErrNoConflictColumns
+}
+
+// Возвращает строку запроса "добавить или обновить" для диалекта params.Dialect:
+// POSTGRES/SQLITE - INSERT ... ON CONFLICT (Columns) DO NOTHING | DO UPDATE SET c = EXCLUDED.c [RETURNING ColumnName]
+// MYSQL - INSERT ... ON DUPLICATE KEY UPDATE c = VALUES(c)
+// SQLSERVER - MERGE INTO ... USING (VALUES (...)) ... WHEN MATCHED THEN UPDATE ... WHEN NOT MATCHED THEN INSERT ... [OUTPUT INSERTED.ColumnName];
+// Порядок аргументов такой же как и у INSERT, без DoNothing столбцы конфликта обязательны (см. ValidateOnConflict)
+// ============================================================================================================================================================
+// Returns the "insert or update" query string for the params.Dialect dialect:
+// POSTGRES/SQLITE - INSERT ... ON CONFLICT (Columns) DO NOTHING | DO UPDATE SET c = EXCLUDED.c [RETURNING ColumnName]
+// MYSQL - INSERT ... ON DUPLICATE KEY UPDATE c = VALUES(c)
+// SQLSERVER - MERGE INTO ... USING (VALUES (...)) ... WHEN MATCHED THEN UPDATE ... WHEN NOT MATCHED THEN INSERT ... [OUTPUT INSERTED.ColumnName];
+// The order of the arguments is the same as for INSERT, without DoNothing the conflict columns are required (see ValidateOnConflict)
+func GetUpsertQuery(params QueryConfig) string {
+	if params.Item == nil {
+		return "ItemToAdd is nil fix that"
+	}
+
+	typeOfN := ConversionValToNonRefType(params.Item)
+
+	tbname := params.TableName
+
+	if len(tbname) == 0 {
+		tbname = typeOfN.Name()
+	}
+
+	tbname = params.wrap(tbname)
+
+	if ValidateOnConflict(params) != nil {
+		return "OnConflict.Columns is empty fix that"
+	}
+
+	fields := QueryFields(params, UPSERT, true)
+	if len(fields) == 0 {
+		return "ItemToAdd has no columns fix that"
+	}
+
+	columns := make([]string, len(fields))
+	for idx, field := range fields {
+		columns[idx] = field.Column
+	}
+
+	updateColumns := params.OnConflict.UpdateColumns
+
+	// по умолчанию обновляются столбцы вставки, которые записывает UPDATE, кроме столбцов конфликта
+	if len(updateColumns) == 0 {
+		for _, field := range fields {
+			if field.Tag.Writable(UPDATE) && !slices.Contains(params.OnConflict.Columns, field.Column) {
+				updateColumns = append(updateColumns, field.Column)
+			}
+		}
+	}
+
+	doNothing := params.OnConflict.DoNothing || len(updateColumns) == 0
+
+	returning := params.wrapReturning()
+
+	wrappedColumns := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	for idx, column := range columns {
+		wrappedColumns[idx] = params.wrap(column)
+		placeholders[idx] = params.placeholder(idx + 1)
+	}
+
+	if params.Dialect == SQLSERVER {
+		return getMergeQuery(params, tbname, columns, wrappedColumns, placeholders, updateColumns, doNothing, returning)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("INSERT INTO " + tbname + " (" + strings.Join(wrappedColumns, ", ") + ") VALUES (" + strings.Join(placeholders, ",") + ")")
+
+	if params.Dialect == MYSQL {
+		builder.WriteString(" ON DUPLICATE KEY UPDATE ")
+		if doNothing {
+			// обновление столбца самим собой ничего не меняет, но в отличие от INSERT IGNORE не скрывает другие ошибки
+			first := wrappedColumns[0]
+			if len(params.OnConflict.Columns) > 0 {
+				first = params.wrap(params.OnConflict.Columns[0])
+			}
+			builder.WriteString(first + " = " + first)
+			return builder.String()
+		}
+		for idx, column := range updateColumns {
+			if idx > 0 {
+				builder.WriteString(", ")
+			}
+			wrapped := params.wrap(column)
+			builder.WriteString(wrapped + " = VALUES(" + wrapped + ")")
+		}
+		return builder.String()
+	}
+
+	builder.WriteString(" ON CONFLICT")
+	if len(params.OnConflict.Columns) > 0 {
+		conflict := make([]string, len(params.OnConflict.Columns))
+		for idx, column := range params.OnConflict.Columns {
+			conflict[idx] = params.wrap(column)
+		}
+		builder.WriteString(" (" + strings.Join(conflict, ", ") + ")")
+	}
+
+	if doNothing {
+		builder.WriteString(" DO NOTHING")
+	} else {
+		builder.WriteString(" DO UPDATE SET ")
+		for idx, column := range updateColumns {
+			if idx > 0 {
+				builder.WriteString(", ")
+			}
+			wrapped := params.wrap(column)
+			builder.WriteString(wrapped + " = EXCLUDED." + wrapped)
+		}
+	}
+
+	builder.WriteString(params.Dialect.returningClause(returning))
+
+	return builder.String()
+}
+
+func getMergeQuery(params QueryConfig, tbname string, columns, wrappedColumns, placeholders, updateColumns []string, doNothing bool, returning []string) string {
+	var builder strings.Builder
+
+	builder.WriteString("MERGE INTO " + tbname + " WITH (HOLDLOCK) AS target USING (VALUES (" + strings.Join(placeholders, ",") + ")) AS source (" + strings.Join(wrappedColumns, ", ") + ") ON ")
+
+	if len(params.OnConflict.Columns) == 0 {
+		builder.WriteString("1 = 0")
+	}
+
+	for idx, column := range params.OnConflict.Columns {
+		if idx > 0 {
+			builder.WriteString(" AND ")
+		}
+		wrapped := params.wrap(column)
+		builder.WriteString("target." + wrapped + " = source." + wrapped)
+	}
+
+	if !doNothing {
+		builder.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+		for idx, column := range updateColumns {
+			if idx > 0 {
+				builder.WriteString(", ")
+			}
+			wrapped := params.wrap(column)
+			builder.WriteString("target." + wrapped + " = source." + wrapped)
+		}
+	}
+
+	sourceColumns := make([]string, len(columns))
+	for idx, column := range wrappedColumns {
+		sourceColumns[idx] = "source." + column
+	}
+
+	builder.WriteString(" WHEN NOT MATCHED THEN INSERT (" + strings.Join(wrappedColumns, ", ") + ") VALUES (" + strings.Join(sourceColumns, ", ") + ")")
+	builder.WriteString(params.Dialect.returningClause(returning))
+	builder.WriteString(";")
+
+	return builder.String()
+}
+
+//endregion
@@ -0,0 +1,64 @@
+package sqlstrings
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpsertQuery(t *testing.T) {
+	query := QueryConfig{
+		TableName:    tableName,
+		ColumnName:   columnName,
+		Item:         user2{},
+		ExcludedTags: []string{"Description"},
+		OnConflict:   OnConflict{Columns: []string{"Id"}},
+	}
+
+	cases := []struct {
+		name     string
+		res      string
+		expected string
+	}{
+		{"postgres update", GetUpsertQuery(query),
+			"INSERT INTO users (Id, Name, Password) VALUES ($1,$2,$3) ON CONFLICT (Id) DO UPDATE SET Name = EXCLUDED.Name, Password = EXCLUDED.Password RETURNING Id"},
+		{"postgres nothing", GetUpsertQuery(query.ChangeOnConflict(OnConflict{Columns: []string{"Id"}, DoNothing: true})),
+			"INSERT INTO users (Id, Name, Password) VALUES ($1,$2,$3) ON CONFLICT (Id) DO NOTHING RETURNING Id"},
+		{"postgres update columns", GetUpsertQuery(query.ChangeOnConflict(OnConflict{Columns: []string{"Id"}, UpdateColumns: []string{"Name"}}).ChangeNameWrapper(wrapper)),
+			"INSERT INTO \"users\" (\"Id\", \"Name\", \"Password\") VALUES ($1,$2,$3) ON CONFLICT (\"Id\") DO UPDATE SET \"Name\" = EXCLUDED.\"Name\" RETURNING \"Id\""},
+		{"mysql update", GetUpsertQuery(query.ChangeDialect(MYSQL)),
+			"INSERT INTO `users` (`Id`, `Name`, `Password`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `Name` = VALUES(`Name`), `Password` = VALUES(`Password`)"},
+		{"mysql nothing", GetUpsertQuery(query.ChangeDialect(MYSQL).ChangeOnConflict(OnConflict{Columns: []string{"Id"}, DoNothing: true})),
+			"INSERT INTO `users` (`Id`, `Name`, `Password`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `Id` = `Id`"},
+		{"sqlserver merge", GetUpsertQuery(query.ChangeDialect(SQLSERVER)),
+			"MERGE INTO [users] WITH (HOLDLOCK) AS target USING (VALUES (@p1,@p2,@p3)) AS source ([Id], [Name], [Password]) ON target.[Id] = source.[Id] " +
+				"WHEN MATCHED THEN UPDATE SET target.[Name] = source.[Name], target.[Password] = source.[Password] " +
+				"WHEN NOT MATCHED THEN INSERT ([Id], [Name], [Password]) VALUES (source.[Id], source.[Name], source.[Password]) OUTPUT INSERTED.[Id];"},
+	}
+
+	for _, c := range cases {
+		if c.res != c.expected {
+			t.Errorf("%s: QUERIES NOT MATCH\n%s\n%s", c.name, c.expected, c.res)
+		}
+	}
+}
+
+func TestUpsertConflictColumns(t *testing.T) {
+	query := QueryConfig{TableName: tableName, Item: user2{}}
+
+	if err := ValidateOnConflict(query); !errors.Is(err, ErrNoConflictColumns) {
+		t.Errorf("expected ErrNoConflictColumns, got %v", err)
+	}
+
+	if res := GetUpsertQuery(query.ChangeDialect(SQLITE)); res != "OnConflict.Columns is empty fix that" {
+		t.Errorf("ON CONFLICT DO UPDATE without columns must not be generated: %s", res)
+	}
+
+	// DoNothing и MYSQL не требуют столбцов конфликта
+	if err := ValidateOnConflict(query.ChangeOnConflict(OnConflict{DoNothing: true})); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if err := ValidateOnConflict(query.ChangeDialect(MYSQL)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
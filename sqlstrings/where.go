@@ -0,0 +1,450 @@
+package sqlstrings
+
+import (
+	"slices"
+	"strings"
+)
+
+// Условие для WHERE, создается функциями Eq, Ne, Lt, Lte, Gt, Gte, Like, In, NotIn, IsNull, IsNotNull, Between, And, Or, Not
+// Имя столбца может содержать имя таблицы через точку (Users.Id), каждая часть оборачивается отдельно
+// ======================================================================================
+// Condition for WHERE, it is created by the Eq, Ne, Lt, Lte, Gt, Gte, Like, In, NotIn, IsNull, IsNotNull, Between, And, Or, Not functions
+// The column name may contain a table name separated by a dot (Users.Id), every part is wrapped separately
+type Condition interface {
+	render(w *conditionWriter, nested bool)
+}
+
+type conditionWriter struct {
+	params     QueryConfig
+	builder    strings.Builder
+	argIdx     int
+	args       []any
+	argColumns []string
+}
+
+func (w *conditionWriter) column(name string) {
+	parts := strings.Split(name, ".")
+	for idx, part := range parts {
+		if idx > 0 {
+			w.builder.WriteString(".")
+		}
+		w.builder.WriteString(w.params.wrap(part))
+	}
+}
+
+func (w *conditionWriter) arg(column string, value any) {
+	w.builder.WriteString(w.params.placeholder(w.argIdx))
+	w.argIdx++
+	w.args = append(w.args, value)
+	w.argColumns = append(w.argColumns, column)
+}
+
+//region Conditions
+
+type comparison struct {
+	column   string
+	operator string
+	value    any
+}
+
+func (c comparison) render(w *conditionWriter, _ bool) {
+	w.column(c.column)
+	w.builder.WriteString(" " + c.operator + " ")
+	w.arg(c.column, c.value)
+}
+
+type columnComparison struct {
+	left     string
+	operator string
+	right    string
+}
+
+func (c columnComparison) render(w *conditionWriter, _ bool) {
+	w.column(c.left)
+	w.builder.WriteString(" " + c.operator + " ")
+	w.column(c.right)
+}
+
+type inList struct {
+	column string
+	values []any
+	not    bool
+}
+
+func (c inList) render(w *conditionWriter, _ bool) {
+	// пустой список: IN () невалидный SQL, поэтому подставляем всегда ложное/истинное условие
+	if len(c.values) == 0 {
+		if c.not {
+			w.builder.WriteString("1 = 1")
+		} else {
+			w.builder.WriteString("1 = 0")
+		}
+		return
+	}
+
+	w.column(c.column)
+	if c.not {
+		w.builder.WriteString(" NOT IN (")
+	} else {
+		w.builder.WriteString(" IN (")
+	}
+	for idx, value := range c.values {
+		if idx > 0 {
+			w.builder.WriteString(", ")
+		}
+		w.arg(c.column, value)
+	}
+	w.builder.WriteString(")")
+}
+
+type isNull struct {
+	column string
+	not    bool
+}
+
+func (c isNull) render(w *conditionWriter, _ bool) {
+	w.column(c.column)
+	if c.not {
+		w.builder.WriteString(" IS NOT NULL")
+	} else {
+		w.builder.WriteString(" IS NULL")
+	}
+}
+
+type between struct {
+	column string
+	from   any
+	to     any
+}
+
+func (c between) render(w *conditionWriter, _ bool) {
+	w.column(c.column)
+	w.builder.WriteString(" BETWEEN ")
+	w.arg(c.column, c.from)
+	w.builder.WriteString(" AND ")
+	w.arg(c.column, c.to)
+}
+
+type group struct {
+	operator   string
+	conditions []Condition
+}
+
+func (c group) render(w *conditionWriter, nested bool) {
+	var conditions []Condition
+	for _, cond := range c.conditions {
+		if cond != nil {
+			conditions = append(conditions, cond)
+		}
+	}
+
+	if len(conditions) == 0 {
+		if c.operator == "OR" {
+			w.builder.WriteString("1 = 0")
+		} else {
+			w.builder.WriteString("1 = 1")
+		}
+		return
+	}
+
+	if len(conditions) == 1 {
+		conditions[0].render(w, nested)
+		return
+	}
+
+	if nested {
+		w.builder.WriteString("(")
+	}
+	for idx, cond := range conditions {
+		if idx > 0 {
+			w.builder.WriteString(" " + c.operator + " ")
+		}
+		cond.render(w, true)
+	}
+	if nested {
+		w.builder.WriteString(")")
+	}
+}
+
+type not struct {
+	condition Condition
+}
+
+func (c not) render(w *conditionWriter, _ bool) {
+	w.builder.WriteString("NOT ")
+	if c.condition == nil {
+		w.builder.WriteString("1 = 1")
+		return
+	}
+	// NOT всегда оборачивает вложенное условие в скобки
+	w.builder.WriteString("(")
+	c.condition.render(w, false)
+	w.builder.WriteString(")")
+}
+
+type seek struct {
+	orders []Order
+	values []any
+}
+
+func (c seek) render(w *conditionWriter, nested bool) {
+	count := min(len(c.orders), len(c.values))
+	if count == 0 {
+		w.builder.WriteString("1 = 1")
+		return
+	}
+
+	sameDirection := true
+	for _, order := range c.orders[:count] {
+		sameDirection = sameDirection && order.Desc == c.orders[0].Desc
+	}
+
+	operator := func(order Order) string {
+		if order.Desc {
+			return " < "
+		}
+		return " > "
+	}
+
+	if count == 1 {
+		w.column(c.orders[0].Column)
+		w.builder.WriteString(operator(c.orders[0]))
+		w.arg(c.orders[0].Column, c.values[0])
+		return
+	}
+
+	// сравнение кортежей (a, b) > ($1, $2) возможно только при одинаковом направлении сортировки, SQLSERVER его не поддерживает
+	if sameDirection && w.params.Dialect != SQLSERVER {
+		w.builder.WriteString("(")
+		for idx, order := range c.orders[:count] {
+			if idx > 0 {
+				w.builder.WriteString(", ")
+			}
+			w.column(order.Column)
+		}
+		w.builder.WriteString(")" + operator(c.orders[0]) + "(")
+		for idx, value := range c.values[:count] {
+			if idx > 0 {
+				w.builder.WriteString(", ")
+			}
+			w.arg(c.orders[idx].Column, value)
+		}
+		w.builder.WriteString(")")
+		return
+	}
+
+	// a > $1 OR (a = $2 AND b > $3) ...
+	if nested {
+		w.builder.WriteString("(")
+	}
+	for i := range count {
+		if i > 0 {
+			w.builder.WriteString(" OR (")
+		}
+		for j := range i {
+			w.column(c.orders[j].Column)
+			w.builder.WriteString(" = ")
+			w.arg(c.orders[j].Column, c.values[j])
+			w.builder.WriteString(" AND ")
+		}
+		w.column(c.orders[i].Column)
+		w.builder.WriteString(operator(c.orders[i]))
+		w.arg(c.orders[i].Column, c.values[i])
+		if i > 0 {
+			w.builder.WriteString(")")
+		}
+	}
+	if nested {
+		w.builder.WriteString(")")
+	}
+}
+
+// column = value
+func Eq(column string, value any) Condition {
+	return comparison{column: column, operator: "=", value: value}
+}
+
+// column <> value
+func Ne(column string, value any) Condition {
+	return comparison{column: column, operator: "<>", value: value}
+}
+
+// column < value
+func Lt(column string, value any) Condition {
+	return comparison{column: column, operator: "<", value: value}
+}
+
+// column <= value
+func Lte(column string, value any) Condition {
+	return comparison{column: column, operator: "<=", value: value}
+}
+
+// column > value
+func Gt(column string, value any) Condition {
+	return comparison{column: column, operator: ">", value: value}
+}
+
+// column >= value
+func Gte(column string, value any) Condition {
+	return comparison{column: column, operator: ">=", value: value}
+}
+
+// column LIKE pattern
+func Like(column string, pattern any) Condition {
+	return comparison{column: column, operator: "LIKE", value: pattern}
+}
+
+// left = right, сравнение двух столбцов, например для ON / comparison of two columns, e.g. for ON
+func EqCol(left string, right string) Condition {
+	return columnComparison{left: left, operator: "=", right: right}
+}
+
+// left <> right
+func NeCol(left string, right string) Condition {
+	return columnComparison{left: left, operator: "<>", right: right}
+}
+
+// left < right
+func LtCol(left string, right string) Condition {
+	return columnComparison{left: left, operator: "<", right: right}
+}
+
+// left <= right
+func LteCol(left string, right string) Condition {
+	return columnComparison{left: left, operator: "<=", right: right}
+}
+
+// left > right
+func GtCol(left string, right string) Condition {
+	return columnComparison{left: left, operator: ">", right: right}
+}
+
+// left >= right
+func GteCol(left string, right string) Condition {
+	return columnComparison{left: left, operator: ">=", right: right}
+}
+
+// column IN (values...), пустой список всегда ложен / an empty list is always false
+func In(column string, values ...any) Condition {
+	return inList{column: column, values: values}
+}
+
+// column NOT IN (values...), пустой список всегда истинен / an empty list is always true
+func NotIn(column string, values ...any) Condition {
+	return inList{column: column, values: values, not: true}
+}
+
+// column IS NULL
+func IsNull(column string) Condition {
+	return isNull{column: column}
+}
+
+// column IS NOT NULL
+func IsNotNull(column string) Condition {
+	return isNull{column: column, not: true}
+}
+
+// column BETWEEN from AND to
+func Between(column string, from any, to any) Condition {
+	return between{column: column, from: from, to: to}
+}
+
+// cond1 AND cond2 ..., nil условия пропускаются / nil conditions are skipped
+func And(conditions ...Condition) Condition {
+	return group{operator: "AND", conditions: conditions}
+}
+
+// cond1 OR cond2 ..., nil условия пропускаются / nil conditions are skipped
+func Or(conditions ...Condition) Condition {
+	return group{operator: "OR", conditions: conditions}
+}
+
+// NOT (cond)
+func Not(condition Condition) Condition {
+	return not{condition: condition}
+}
+
+// Условие для keyset пагинации: строки, идущие после кортежа values в порядке сортировки orders, значения столбцов ключа не должны быть NULL
+// При одинаковом направлении сортировки генерируется (a, b) > ($1, $2), иначе a > $1 OR (a = $2 AND b > $3)
+// ======================================================================================
+// Condition for keyset pagination: rows that come after the values tuple in the orders sort order, values of the key columns must not be NULL
+// With the same sort direction (a, b) > ($1, $2) is generated, otherwise a > $1 OR (a = $2 AND b > $3)
+func Seek(orders []Order, values ...any) Condition {
+	return seek{orders: slices.Clone(orders), values: values}
+}
+
+//endregion
+
+//region Rendering
+
+// Возвращает строку условия и его аргументы, нумерация плейсхолдеров начинается с startIdx
+// ======================================================================================
+// Returns the condition string and its arguments, numbering of placeholders starts from startIdx
+func BuildCondition(params QueryConfig, condition Condition, startIdx int) (string, []any) {
+	if condition == nil {
+		return "", nil
+	}
+
+	w := &conditionWriter{params: params, argIdx: startIdx}
+	condition.render(w, false)
+
+	return w.builder.String(), w.args
+}
+
+// Возвращает аргументы params.Where в порядке их появления в строке запроса, их нужно передавать после остальных аргументов
+// ======================================================================================
+// Returns the arguments of params.Where in the order they appear in the query string, they must be passed after the other arguments
+func WhereArgs(params QueryConfig) []any {
+	_, args := BuildCondition(params, params.Where, 1)
+	return args
+}
+
+// Возвращает столбцы аргументов params.Where в том же порядке, что и WhereArgs
+// ======================================================================================
+// Returns the columns of the params.Where arguments in the same order as WhereArgs
+func WhereArgColumns(params QueryConfig) []string {
+	if params.Where == nil {
+		return nil
+	}
+
+	w := &conditionWriter{params: params, argIdx: 1}
+	params.Where.render(w, false)
+
+	return w.argColumns
+}
+
+// Номер первого аргумента Where для SELECT и DELETE
+func (q QueryConfig) whereStartIdx() int {
+	if len(q.ColumnName) > 0 {
+		return 2
+	}
+	return 1
+}
+
+// Дописывает условие WHERE: ColumnName = $columnIdx [AND Where], аргументы Where нумеруются с whereIdx
+func writeWhere(builder *strings.Builder, params QueryConfig, columnIdx int, whereIdx int) {
+	hasColumn := len(params.ColumnName) > 0
+
+	if !hasColumn && params.Where == nil {
+		return
+	}
+
+	builder.WriteString(" WHERE ")
+
+	if hasColumn {
+		builder.WriteString(params.wrap(params.ColumnName) + " = " + params.placeholder(columnIdx))
+	}
+
+	if params.Where == nil {
+		return
+	}
+
+	w := &conditionWriter{params: params, argIdx: whereIdx}
+	if hasColumn {
+		builder.WriteString(" AND ")
+	}
+	params.Where.render(w, hasColumn)
+	builder.WriteString(w.builder.String())
+}
+
+//endregion
@@ -0,0 +1,93 @@
+package sqlstrings
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestWhereQueries(t *testing.T) {
+	query := QueryConfig{
+		TableName:    tableName,
+		Item:         user2{},
+		ExcludedTags: []string{"Id"},
+		Where: And(
+			Eq("Name", "bob"),
+			Or(Gte("Id", 10), IsNull("Description")),
+			In("Id", 1, 2, 3),
+			Not(Like("Password", "%123%")),
+			Between("Id", 1, 100),
+		),
+	}
+
+	whereStr := "Name = $%d AND (Id >= $%d OR Description IS NULL) AND Id IN ($%d, $%d, $%d) AND NOT (Password LIKE $%d) AND Id BETWEEN $%d AND $%d"
+	expectedArgs := []any{"bob", 10, 1, 2, 3, "%123%", 1, 100}
+
+	cases := []struct {
+		name     string
+		res      string
+		expected string
+	}{
+		{"select", GetSelectQuery(query), "SELECT Name, Password, Description FROM users WHERE " + numbered(whereStr, 1)},
+		{"select column", GetSelectQuery(query.ChangeColumnName(columnName)), "SELECT Name, Password, Description FROM users WHERE Id = $1 AND (" + numbered(whereStr, 2) + ")"},
+		{"update", GetUpdateQuery(query), "UPDATE users SET Name = $1, Password = $2, Description = $3 WHERE " + numbered(whereStr, 4)},
+		{"update column", GetUpdateQuery(query.ChangeColumnName(columnName)), "UPDATE users SET Name = $2, Password = $3, Description = $4 WHERE Id = $1 AND (" + numbered(whereStr, 5) + ")"},
+		{"delete", GetDeleteQuery(query), "DELETE FROM users WHERE " + numbered(whereStr, 1)},
+		{"select cached", GetSelectQueryCached(query), "SELECT Name, Password, Description FROM users WHERE " + numbered(whereStr, 1)},
+		{"mysql", GetDeleteQuery(query.ChangeDialect(MYSQL).ChangeWhere(And(Eq("a", 1), Eq("t.b", 2))).ChangeNameWrapper(wrapper)), "DELETE FROM `users` WHERE `a` = ? AND `t`.`b` = ?"},
+		{"empty in", GetDeleteQuery(query.ChangeWhere(Or(In("Id"), NotIn("Id")))), "DELETE FROM users WHERE 1 = 0 OR 1 = 1"},
+	}
+
+	for _, c := range cases {
+		if c.res != c.expected {
+			t.Errorf("%s: QUERIES NOT MATCH\n%s\n%s", c.name, c.expected, c.res)
+		}
+	}
+
+	if args := WhereArgs(query); !slices.Equal(args, expectedArgs) {
+		t.Errorf("where args not match %v", args)
+	}
+
+	expectedColumns := []string{"Name", "Id", "Id", "Id", "Id", "Password", "Id", "Id"}
+	if columns := WhereArgColumns(query); !slices.Equal(columns, expectedColumns) {
+		t.Errorf("where arg columns not match %v", columns)
+	}
+
+	seekColumns := WhereArgColumns(query.ChangeWhere(Seek([]Order{Desc("Name"), Asc("Id")}, "bob", 3)))
+	if !slices.Equal(seekColumns, []string{"Name", "Name", "Id"}) {
+		t.Errorf("seek arg columns not match %v", seekColumns)
+	}
+
+	if GetSelectQueryCached(query.ChangeWhere(Eq("Name", 1))) == GetSelectQueryCached(query.ChangeWhere(Eq("Password", 1))) {
+		t.Errorf("conditions must not be cached")
+	}
+}
+
+func numbered(format string, start int) string {
+	var args []any
+	for i := range 8 {
+		args = append(args, start+i)
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func TestSeekCondition(t *testing.T) {
+	orders := []Order{Asc("Name"), Asc("Id")}
+
+	cases := []struct {
+		name     string
+		res      string
+		expected string
+	}{
+		{"row values", GetSelectQuery(QueryConfig{TableName: tableName, Item: user2{}, Where: Seek(orders, "bob", 3)}), "SELECT Id, Name, Password, Description FROM users WHERE (Name, Id) > ($1, $2)"},
+		{"single", GetSelectQuery(QueryConfig{TableName: tableName, Item: user2{}, Where: Seek(orders[:1], "bob")}), "SELECT Id, Name, Password, Description FROM users WHERE Name > $1"},
+		{"mixed", GetSelectQuery(QueryConfig{TableName: tableName, Item: user2{}, Where: And(Eq("Password", "p"), Seek([]Order{Desc("Name"), Asc("Id")}, "bob", 3))}), "SELECT Id, Name, Password, Description FROM users WHERE Password = $1 AND (Name < $2 OR (Name = $3 AND Id > $4))"},
+		{"sqlserver", GetSelectQuery(QueryConfig{TableName: tableName, Item: user2{}, Dialect: SQLSERVER, Where: Seek(orders, "bob", 3)}), "SELECT [Id], [Name], [Password], [Description] FROM [users] WHERE [Name] > @p1 OR ([Name] = @p2 AND [Id] > @p3)"},
+	}
+
+	for _, c := range cases {
+		if c.res != c.expected {
+			t.Errorf("%s: QUERIES NOT MATCH\n%s\n%s", c.name, c.expected, c.res)
+		}
+	}
+}
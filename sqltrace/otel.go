@@ -0,0 +1,93 @@
+package sqltrace
+
+import (
+	"context"
+
+	"github.com/RostokaVitaliyRIS211b/gosql"
+)
+
+// Атрибут количества строк, который добавляется при завершении спана / Attribute of the number of rows that is added when the span ends
+const AttrRows = "db.response.returned_rows"
+
+// Вид спана, значения совпадают с trace.SpanKind / Kind of the span, the values match trace.SpanKind
+type SpanKind int
+
+const (
+	SPANKINDUNSPECIFIED SpanKind = iota
+	SPANKINDINTERNAL
+	SPANKINDSERVER
+	SPANKINDCLIENT
+)
+
+// Статус спана, значения совпадают с codes.Code / Status of the span, the values match codes.Code
+type StatusCode int
+
+const (
+	STATUSUNSET StatusCode = iota
+	STATUSERROR
+	STATUSOK
+)
+
+// Аналог attribute.KeyValue / Counterpart of attribute.KeyValue
+type KeyValue struct {
+	Key   string
+	Value any
+}
+
+// Подмножество trace.Tracer из OpenTelemetry API, опции заменены явными параметрами, чтобы пакет не зависел от otel
+// Обертка над trace.Tracer передает kind в trace.WithSpanKind, а attrs в trace.WithAttributes
+// ======================================================================================
+// Subset of trace.Tracer from the OpenTelemetry API, options are replaced with explicit parameters so that the package does not depend on otel
+// A wrapper around trace.Tracer passes kind to trace.WithSpanKind and attrs to trace.WithAttributes
+type OTelTracer interface {
+	Start(context context.Context, spanName string, kind SpanKind, attrs ...KeyValue) (context.Context, OTelSpan)
+}
+
+// Подмножество trace.Span из OpenTelemetry API / Subset of trace.Span from the OpenTelemetry API
+type OTelSpan interface {
+	SetAttributes(attrs ...KeyValue)
+	RecordError(err error)
+	SetStatus(code StatusCode, description string)
+	End()
+}
+
+// Возвращает gosql.Tracer, который начинает клиентские спаны через tracer, при ошибке вызываются RecordError и SetStatus(STATUSERROR)
+// ======================================================================================
+// Returns a gosql.Tracer that starts client spans via tracer, on error RecordError and SetStatus(STATUSERROR) are called
+func GetOTelTracer(tracer OTelTracer) gosql.Tracer {
+	return &otelAdapter{tracer: tracer}
+}
+
+type otelAdapter struct {
+	tracer OTelTracer
+}
+
+func (a *otelAdapter) StartSpan(context context.Context, name string, attrs []gosql.Attribute) (context.Context, gosql.Span) {
+	kvs := make([]KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		if len(attr.Value) > 0 {
+			kvs = append(kvs, KeyValue{Key: attr.Key, Value: attr.Value})
+		}
+	}
+
+	context, span := a.tracer.Start(context, name, SPANKINDCLIENT, kvs...)
+
+	return context, &otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span OTelSpan
+}
+
+func (s *otelSpan) End(err error, rows int) {
+	if rows >= 0 {
+		s.span.SetAttributes(KeyValue{Key: AttrRows, Value: int64(rows)})
+	}
+
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(STATUSERROR, err.Error())
+	}
+
+	s.span.End()
+}
@@ -0,0 +1,88 @@
+package sqltrace
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/RostokaVitaliyRIS211b/gosql"
+)
+
+// Name - имя спана ;
+// Attributes - атрибуты, с которыми начат спан ;
+// Err - ошибка, с которой завершен спан ;
+// Rows - количество строк, с которым завершен спан ;
+// Ended - спан завершен ;
+// ======================================================================================
+// Name - span name ;
+// Attributes - attributes the span was started with ;
+// Err - error the span was ended with ;
+// Rows - number of rows the span was ended with ;
+// Ended - the span is ended ;
+type RecordedSpan struct {
+	Name       string
+	Attributes []gosql.Attribute
+	Err        error
+	Rows       int
+	Ended      bool
+}
+
+// Возвращает значение атрибута key или пустую строку / Returns the value of the key attribute or an empty string
+func (s RecordedSpan) Attribute(key string) string {
+	idx := slices.IndexFunc(s.Attributes, func(attr gosql.Attribute) bool { return attr.Key == key })
+	if idx < 0 {
+		return ""
+	}
+	return s.Attributes[idx].Value
+}
+
+// Трассировщик, который хранит спаны в памяти, для тестов
+// ======================================================================================
+// Tracer that keeps spans in memory, for tests
+type Recorder struct {
+	mutex sync.Mutex
+	spans []*RecordedSpan
+}
+
+func (r *Recorder) StartSpan(context context.Context, name string, attrs []gosql.Attribute) (context.Context, gosql.Span) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	span := &RecordedSpan{Name: name, Attributes: slices.Clone(attrs), Rows: -1}
+	r.spans = append(r.spans, span)
+
+	return context, &recorderSpan{recorder: r, span: span}
+}
+
+// Возвращает копии записанных спанов в порядке начала / Returns copies of the recorded spans in start order
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	res := make([]RecordedSpan, len(r.spans))
+	for idx, span := range r.spans {
+		res[idx] = *span
+	}
+	return res
+}
+
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.spans = nil
+}
+
+type recorderSpan struct {
+	recorder *Recorder
+	span     *RecordedSpan
+}
+
+func (s *recorderSpan) End(err error, rows int) {
+	s.recorder.mutex.Lock()
+	defer s.recorder.mutex.Unlock()
+
+	s.span.Err = err
+	s.span.Rows = rows
+	s.span.Ended = true
+}
@@ -0,0 +1,169 @@
+package sqltrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type traceKey struct{}
+
+type fakeHandler struct {
+	contexts []context.Context
+	err      error
+}
+
+func (f *fakeHandler) SelectContext(context context.Context, _ any, _ string, _ sqlstrings.QueryConfig, _ ...any) error {
+	f.contexts = append(f.contexts, context)
+	return f.err
+}
+
+func (f *fakeHandler) InsertContext(context context.Context, _ string, _ sqlstrings.QueryConfig, _ ...any) (int, error) {
+	f.contexts = append(f.contexts, context)
+	return 1, f.err
+}
+
+func (f *fakeHandler) ExecContext(context context.Context, _ string, _ sqlstrings.QueryConfig, _ ...any) (int, error) {
+	f.contexts = append(f.contexts, context)
+	return 3, f.err
+}
+
+func (f *fakeHandler) Select(dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return f.SelectContext(context.Background(), dest, query, queryConfig, args...)
+}
+
+func (f *fakeHandler) Insert(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return f.InsertContext(context.Background(), query, queryConfig, args...)
+}
+
+func (f *fakeHandler) Exec(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return f.ExecContext(context.Background(), query, queryConfig, args...)
+}
+
+type user struct {
+	Id   int    `db:"Id,pk,auto"`
+	Name string `db:"Name"`
+}
+
+func TestRecorder(t *testing.T) {
+	db := gosql.GetDb(nil, "main")
+	handler := &fakeHandler{}
+	db.ChangeHandler(handler)
+	db.SetDialect(sqlstrings.POSTGRES)
+
+	recorder := &Recorder{}
+	db.SetTracer(recorder)
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db"}
+	ctx := context.WithValue(context.Background(), traceKey{}, "caller")
+
+	var users []user
+	if err := db.SelectContext(ctx, qc.ChangeItem(user{}), &users); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := db.UpdateItemContext(ctx, qc, user{Id: 1, Name: "bob"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	handler.err = errors.New("boom")
+	db.ExecContext(ctx, "TRUNCATE users")
+
+	spans := recorder.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("spans not recorded %v", spans)
+	}
+
+	if spans[0].Name != "select users" || spans[0].Attribute(gosql.AttrDbSystem) != "postgresql" || spans[0].Attribute(gosql.AttrDbStatement) != `SELECT "Id", "Name" FROM "users"` {
+		t.Errorf("select span not match %+v", spans[0])
+	}
+
+	if spans[1].Name != "update users" || spans[1].Rows != 3 || !spans[1].Ended {
+		t.Errorf("update span not match %+v", spans[1])
+	}
+
+	if spans[2].Name != "exec" || spans[2].Err == nil {
+		t.Errorf("exec span not match %+v", spans[2])
+	}
+
+	for _, context := range handler.contexts {
+		if context.Value(traceKey{}) != "caller" {
+			t.Errorf("caller context must be propagated to the handler")
+		}
+	}
+
+	// nil отключает трассировку
+	db.SetTracer(nil)
+	recorder.Reset()
+	db.ExecContext(ctx, "TRUNCATE users")
+	if len(recorder.Spans()) != 0 {
+		t.Errorf("tracing must be disabled")
+	}
+}
+
+type fakeOTelSpan struct {
+	attrs  []KeyValue
+	status StatusCode
+	errs   []error
+	ended  bool
+}
+
+func (s *fakeOTelSpan) SetAttributes(attrs ...KeyValue) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *fakeOTelSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *fakeOTelSpan) SetStatus(code StatusCode, _ string) {
+	s.status = code
+}
+
+func (s *fakeOTelSpan) End() {
+	s.ended = true
+}
+
+type fakeOTelTracer struct {
+	names []string
+	kinds []SpanKind
+	spans []*fakeOTelSpan
+}
+
+func (f *fakeOTelTracer) Start(context context.Context, spanName string, kind SpanKind, attrs ...KeyValue) (context.Context, OTelSpan) {
+	span := &fakeOTelSpan{attrs: attrs}
+	f.names = append(f.names, spanName)
+	f.kinds = append(f.kinds, kind)
+	f.spans = append(f.spans, span)
+	return context, span
+}
+
+func TestOTelAdapter(t *testing.T) {
+	db := gosql.GetDb(nil, "main")
+	handler := &fakeHandler{err: errors.New("boom")}
+	db.ChangeHandler(handler)
+
+	tracer := &fakeOTelTracer{}
+	db.SetTracer(GetOTelTracer(tracer))
+
+	db.Delete(sqlstrings.QueryConfig{TableName: "users"})
+
+	if len(tracer.spans) != 1 || tracer.names[0] != "delete users" || tracer.kinds[0] != SPANKINDCLIENT {
+		t.Fatalf("span not started %v %v", tracer.names, tracer.kinds)
+	}
+
+	span := tracer.spans[0]
+	if !span.ended || span.status != STATUSERROR || len(span.errs) != 1 {
+		t.Errorf("span not ended with error %+v", span)
+	}
+
+	// у запроса с ошибкой количество строк неизвестно
+	for _, attr := range span.attrs {
+		if attr.Key == AttrRows {
+			t.Errorf("rows attribute must not be set %+v", span.attrs)
+		}
+	}
+}
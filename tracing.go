@@ -0,0 +1,108 @@
+package gosql
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Атрибуты спана по семантическим соглашениям OpenTelemetry / Span attributes by the OpenTelemetry semantic conventions
+const (
+	AttrDbSystem    = "db.system"
+	AttrDbStatement = "db.statement"
+	AttrDbOperation = "db.operation"
+	AttrDbTable     = "db.sql.table"
+	AttrDbId        = "db.instance.id"
+)
+
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Трассировщик запросов, спан начинается перед обращением к обработчику, контекст спана передается обработчику
+// ======================================================================================
+// Query tracer, the span starts before the call to the handler, the context of the span is passed to the handler
+type Tracer interface {
+	StartSpan(context context.Context, name string, attrs []Attribute) (context.Context, Span)
+}
+
+// Спан запроса, rows - количество затронутых или полученных строк, -1 если неизвестно
+// ======================================================================================
+// Span of the query, rows is the number of affected or received rows, -1 if unknown
+type Span interface {
+	End(err error, rows int)
+}
+
+// Возвращает значение db.system для диалекта / Returns the db.system value for the dialect
+func DbSystem(dialect sqlstrings.Dialect) string {
+	switch dialect {
+	case sqlstrings.POSTGRES:
+		return "postgresql"
+	case sqlstrings.MYSQL:
+		return "mysql"
+	case sqlstrings.SQLITE:
+		return "sqlite"
+	case sqlstrings.SQLSERVER:
+		return "mssql"
+	}
+	return "other_sql"
+}
+
+// Включает трассировку запросов, повторный вызов заменяет трассировщик, nil отключает трассировку
+// ======================================================================================
+// Enables tracing of queries, a repeated call replaces the tracer, nil disables tracing
+func (db *DB) SetTracer(tracer Tracer) {
+	db.traceOnce.Do(func() {
+		db.AddHook(&db.traceHook)
+	})
+	db.traceHook.tracer.Store(&tracerHolder{tracer: tracer})
+}
+
+type tracerHolder struct {
+	tracer Tracer
+}
+
+type traceHook struct {
+	tracer atomic.Pointer[tracerHolder]
+}
+
+type spanKey struct{}
+
+func (h *traceHook) Before(context context.Context, event *QueryEvent) context.Context {
+	// пустой спан скрывает спан внешнего запроса, если он есть в контексте
+	holder := h.tracer.Load()
+	if holder == nil || holder.tracer == nil {
+		return withSpan(context, nil)
+	}
+
+	table := tableName(event.Config)
+	name := event.Operation.String()
+	if len(table) > 0 {
+		name += " " + table
+	}
+
+	attrs := []Attribute{
+		{Key: AttrDbSystem, Value: DbSystem(event.Config.Dialect)},
+		{Key: AttrDbStatement, Value: event.Query},
+		{Key: AttrDbOperation, Value: event.Operation.String()},
+		{Key: AttrDbTable, Value: table},
+		{Key: AttrDbId, Value: event.DbId},
+	}
+
+	context, span := holder.tracer.StartSpan(context, name, attrs)
+
+	return withSpan(context, span)
+}
+
+func (h *traceHook) After(context context.Context, event *QueryEvent, err error, _ time.Duration) {
+	if span, ok := context.Value(spanKey{}).(Span); ok && span != nil {
+		span.End(err, event.Rows)
+	}
+}
+
+func withSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
@@ -0,0 +1,258 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+// Обработчик, работающий внутри одной транзакции
+// ======================================================================================
+// Handler that works inside a single transaction
+type TxHandler interface {
+	DbHandler
+	Commit() error
+	Rollback() error
+}
+
+// Обработчик, который умеет начинать транзакции, StdDbHandler реализует этот интерфейс
+// ======================================================================================
+// Handler that is able to begin transactions, StdDbHandler implements this interface
+type TxBeginner interface {
+	BeginTxHandler(context context.Context, opts *sql.TxOptions) (TxHandler, error)
+}
+
+type StdTxHandler struct {
+	tx      *sql.Tx
+	scanner sqlreflect.Scanner
+}
+
+// Транзакция с тем же набором методов что и у DB, после Commit или Rollback использовать нельзя
+// ======================================================================================
+// Transaction with the same set of methods as DB, it must not be used after Commit or Rollback
+type Tx struct {
+	db      *DB
+	handler TxHandler
+}
+
+//region StdTxHandler Realization
+
+func (stdt *StdTxHandler) SelectContext(context context.Context, dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return selectConn(context, stdt.tx, stdt.scanner, dest, query, queryConfig, args...)
+}
+
+func (stdt *StdTxHandler) InsertContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, args ...any) (id int, err error) {
+	return insertConn(context, stdt.tx, query, queryConfig, args...)
+}
+
+func (stdt *StdTxHandler) ExecContext(context context.Context, query string, _ sqlstrings.QueryConfig, args ...any) (int, error) {
+	return execConn(context, stdt.tx, query, args...)
+}
+
+func (stdt *StdTxHandler) Select(dest any, query string, queryConfig sqlstrings.QueryConfig, args ...any) error {
+	return stdt.SelectContext(context.Background(), dest, query, queryConfig, args...)
+}
+
+func (stdt *StdTxHandler) Insert(query string, queryConfig sqlstrings.QueryConfig, args ...any) (id int, err error) {
+	return stdt.InsertContext(context.Background(), query, queryConfig, args...)
+}
+
+func (stdt *StdTxHandler) Exec(query string, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return stdt.ExecContext(context.Background(), query, queryConfig, args...)
+}
+
+func (stdt *StdTxHandler) Commit() error {
+	return stdt.tx.Commit()
+}
+
+func (stdt *StdTxHandler) Rollback() error {
+	return stdt.tx.Rollback()
+}
+
+func (stdt *StdTxHandler) Scanner() sqlreflect.Scanner {
+	return stdt.scanner
+}
+
+func GetStdTxHandler(tx *sql.Tx, scanner sqlreflect.Scanner) TxHandler {
+	if scanner == nil {
+		scanner = sqlreflect.GetScanner()
+	}
+	return &StdTxHandler{
+		tx:      tx,
+		scanner: scanner,
+	}
+}
+
+//endregion
+
+//region DB Transactions
+
+func (db *DB) Begin() (*Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+// Начинает транзакцию, обработчик должен реализовывать TxBeginner
+// ======================================================================================
+// Begins a transaction, the handler must implement TxBeginner
+func (db *DB) BeginTx(context context.Context, opts *sql.TxOptions) (*Tx, error) {
+	db.handlerMutex.RLock()
+	beginner, ok := db.base.(TxBeginner)
+	middlewares := len(db.middlewares) > 0
+	db.handlerMutex.RUnlock()
+
+	if !ok {
+		return nil, errors.New("handler does not support transactions")
+	}
+
+	handler, err := beginner.BeginTxHandler(context, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// запросы транзакции проходят через те же middleware что и запросы DB
+	if middlewares {
+		db.handlerMutex.RLock()
+		handler = &middlewareTxHandler{HandlerWrapper: HandlerWrapper{db.wrapHandler(handler)}, tx: handler}
+		db.handlerMutex.RUnlock()
+	}
+
+	return &Tx{
+		db:      db,
+		handler: handler,
+	}, nil
+}
+
+// Выполняет fn внутри транзакции, если fn возвращает ошибку или паникует, то транзакция откатывается, иначе фиксируется
+// Если задана RetryPolicy, то при повторяемой ошибке вся транзакция выполняется заново, поэтому fn может вызываться несколько раз
+// ======================================================================================
+// Runs fn inside a transaction, if fn returns an error or panics the transaction is rolled back, otherwise it is committed
+// If RetryPolicy is set the whole transaction is run again on a retryable error, so fn may be called several times
+func (db *DB) WithTx(context context.Context, fn func(tx *Tx) error) error {
+	return db.WithTxOptions(context, nil, fn)
+}
+
+func (db *DB) WithTxOptions(context context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) error {
+	return db.retry(context, true, func() error {
+		return db.withTx(context, opts, fn)
+	})
+}
+
+func (db *DB) withTx(context context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) (err error) {
+	tx, err := db.BeginTx(context, opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("rollback failed: %w", rbErr))
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+//endregion
+
+//region Tx Methods
+
+func (tx *Tx) Commit() error {
+	return tx.handler.Commit()
+}
+
+func (tx *Tx) Rollback() error {
+	return tx.handler.Rollback()
+}
+
+// dest должен быть указателем на slice
+// ======================================================================================
+// dest should be a pointer to slice
+func (tx *Tx) SelectQuery(query string, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.SelectQueryContext(context.Background(), query, queryConfig, dest, args...)
+}
+
+func (tx *Tx) SelectQueryContext(context context.Context, query string, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.db.selectQueryWith(context, tx.handler, query, queryConfig, dest, args...)
+}
+
+// dest должен быть указателем на slice
+// ======================================================================================
+// dest should be a pointer to slice
+func (tx *Tx) Select(queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.SelectContext(context.Background(), queryConfig, dest, args...)
+}
+
+func (tx *Tx) SelectContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.db.selectWith(context, tx.handler, queryConfig, dest, args...)
+}
+
+// dest должен быть указателем на структуру
+// ======================================================================================
+// dest should be a pointer to struct
+func (tx *Tx) Get(queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.GetContext(context.Background(), queryConfig, dest, args...)
+}
+
+func (tx *Tx) GetContext(context context.Context, queryConfig sqlstrings.QueryConfig, dest any, args ...any) error {
+	return tx.db.getWith(context, tx.handler, queryConfig, dest, args...)
+}
+
+// Аргументы берутся из queryConfig.Item по тем же правилам что и в DB.Insert
+// ======================================================================================
+// Arguments are taken from queryConfig.Item by the same rules as in DB.Insert
+func (tx *Tx) Insert(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.InsertContext(context.Background(), queryConfig, args...)
+}
+
+func (tx *Tx) InsertContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.db.insertWith(context, tx.handler, queryConfig, args...)
+}
+
+// Аргументы берутся из queryConfig.Item по тем же правилам что и в DB.Update
+// Как и DB.Update, оставлен для совместимости, sql.Result возвращает Tx.UpdateResult
+// ======================================================================================
+// Arguments are taken from queryConfig.Item by the same rules as in DB.Update
+// As DB.Update it is kept for compatibility, sql.Result is returned by Tx.UpdateResult
+func (tx *Tx) Update(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.UpdateContext(context.Background(), queryConfig, args...)
+}
+
+func (tx *Tx) UpdateContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.db.updateWith(context, tx.handler, queryConfig, args...)
+}
+
+// Как и DB.Delete, оставлен для совместимости, sql.Result возвращает Tx.DeleteResult
+// ======================================================================================
+// As DB.Delete it is kept for compatibility, sql.Result is returned by Tx.DeleteResult
+func (tx *Tx) Delete(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.DeleteContext(context.Background(), queryConfig, args...)
+}
+
+func (tx *Tx) DeleteContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.db.deleteWith(context, tx.handler, queryConfig, args...)
+}
+
+// Как и DB.Exec, оставлен для совместимости, sql.Result возвращает Tx.ExecResult
+// ======================================================================================
+// As DB.Exec it is kept for compatibility, sql.Result is returned by Tx.ExecResult
+func (tx *Tx) Exec(query string, args ...any) (int, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+func (tx *Tx) ExecContext(context context.Context, query string, args ...any) (int, error) {
+	return tx.db.execWith(context, tx.handler, query, args...)
+}
+
+//endregion
@@ -0,0 +1,77 @@
+package gosql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+type txUser struct {
+	Id   int    `db:"Id"`
+	Name string `db:"Name"`
+}
+
+func TestWithTx(t *testing.T) {
+	db := GetDb(nil, "test")
+	beginner := &fakeBeginner{}
+	db.ChangeHandler(beginner)
+
+	qc := sqlstrings.QueryConfig{TableName: "users", TagName: "db", ColumnName: "Id", ExcludedTags: []string{"Id"}}
+
+	err := db.WithTx(context.Background(), func(tx *Tx) error {
+		_, err := tx.Insert(qc.ChangeItem(txUser{Name: "test"}))
+		return err
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if !beginner.tx.committed || beginner.tx.rolled {
+		t.Errorf("transaction must be committed")
+	}
+
+	if len(beginner.tx.queries) != 1 || len(beginner.queries) != 0 {
+		t.Errorf("query must go through the transaction handler")
+	}
+
+	myErr := errors.New("boom")
+
+	err = db.WithTx(context.Background(), func(tx *Tx) error {
+		return myErr
+	})
+
+	if !errors.Is(err, myErr) {
+		t.Errorf("error must be returned from WithTx, got %v", err)
+	}
+
+	if beginner.tx.committed || !beginner.tx.rolled {
+		t.Errorf("transaction must be rolled back")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic must be propagated")
+			}
+		}()
+		_ = db.WithTx(context.Background(), func(tx *Tx) error {
+			panic("boom")
+		})
+	}()
+
+	if !beginner.tx.rolled {
+		t.Errorf("transaction must be rolled back on panic")
+	}
+}
+
+func TestBeginTxUnsupported(t *testing.T) {
+	db := GetDb(nil, "test")
+	db.ChangeHandler(&fakeHandler{})
+
+	if _, err := db.BeginTx(context.Background(), nil); err == nil {
+		t.Errorf("handler without TxBeginner must not begin transactions")
+	}
+}
@@ -0,0 +1,91 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlreflect"
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+//region Upsert
+
+// Добавляет запись или обновляет существующую при конфликте по queryConfig.OnConflict.Columns, аргументы берутся из queryConfig.Item по тем же правилам что и в Insert
+// Если диалект поддерживает RETURNING и указан queryConfig.ColumnName, Returning или столбец с опцией auto, то возвращается значение ReturningColumn (0 если при DoNothing запись не была добавлена), иначе количество затронутых строк
+// Столбцы Returning записываются в поля queryConfig.Item, если это указатель на структуру
+// ================================================================================================================================
+// Inserts the record or updates the existing one on conflict by queryConfig.OnConflict.Columns, arguments are taken from queryConfig.Item by the same rules as in Insert
+// If the dialect supports RETURNING and queryConfig.ColumnName, Returning or the column with the auto option is specified, the ReturningColumn value is returned (0 if the record was not inserted with DoNothing), otherwise the number of affected rows
+// The Returning columns are written into the queryConfig.Item fields if it is a pointer to the struct
+func (db *DB) Upsert(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return db.UpsertContext(context.Background(), queryConfig, args...)
+}
+
+func (db *DB) UpsertContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	db.handlerMutex.RLock()
+	defer db.handlerMutex.RUnlock()
+
+	return db.upsertWith(context, db.handler, queryConfig, args...)
+}
+
+func (tx *Tx) Upsert(queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.UpsertContext(context.Background(), queryConfig, args...)
+}
+
+func (tx *Tx) UpsertContext(context context.Context, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	return tx.db.upsertWith(context, tx.handler, queryConfig, args...)
+}
+
+func (db *DB) upsertWith(context context.Context, handler DbHandler, queryConfig sqlstrings.QueryConfig, args ...any) (int, error) {
+	queryConfig = db.prepareConfig(queryConfig)
+	queryConfig.QueryType = sqlstrings.UPSERT
+	context = withOperation(context, OPUPSERT)
+
+	if queryConfig.Item == nil {
+		return -1, ErrNilItem
+	}
+
+	if err := sqlstrings.ValidateOnConflict(queryConfig); err != nil {
+		return -1, err
+	}
+
+	query := sqlstrings.GetUpsertQuery(queryConfig)
+
+	if len(args) == 0 && queryConfig.Item != nil && db.mapper != nil {
+		db.mapperMutex.RLock()
+		typeMap, err := db.mapper.Map(reflect.TypeOf(queryConfig.Item), queryConfig.TagName)
+		db.mapperMutex.RUnlock()
+		if err != nil {
+			return -1, err
+		}
+
+		args = sqlreflect.GetFieldsValuesOfItem(queryConfig, typeMap)
+	}
+
+	if len(queryConfig.ReturningColumns()) == 0 || !queryConfig.Dialect.SupportsReturning() {
+		res, err := handler.ExecContext(context, query, queryConfig, args...)
+		return res, wrapQueryError(err, query, queryConfig)
+	}
+
+	if len(queryConfig.Returning) > 0 {
+		ids, err := db.returningWith(context, handler, query, queryConfig, args...)
+		if err != nil {
+			return -1, err
+		}
+		if len(ids) == 0 {
+			return 0, nil
+		}
+		return ids[0], nil
+	}
+
+	id, err := handler.InsertContext(context, query, queryConfig, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return id, wrapQueryError(err, query, queryConfig)
+}
+
+//endregion
@@ -0,0 +1,47 @@
+package gosql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/RostokaVitaliyRIS211b/gosql/sqlstrings"
+)
+
+func TestUpsert(t *testing.T) {
+	db := GetDb(nil, "test")
+	handler := &fakeHandler{execRes: 1}
+	db.ChangeHandler(handler)
+
+	qc := sqlstrings.QueryConfig{
+		TableName:  "users",
+		TagName:    "db",
+		Item:       Users{Id: 5, Name: "upsert"},
+		OnConflict: sqlstrings.OnConflict{Columns: []string{"Id"}},
+	}
+
+	res, err := db.Upsert(qc)
+	if err != nil || res != 1 {
+		t.Errorf("Upsert failed: %d %v", res, err)
+	}
+
+	expected := "INSERT INTO users (Id, Name) VALUES ($1,$2) ON CONFLICT (Id) DO UPDATE SET Name = EXCLUDED.Name"
+	if handler.queries[0] != expected {
+		t.Errorf("QUERIES NOT MATCH\n%s\n%s", expected, handler.queries[0])
+	}
+
+	if len(handler.args[0]) != 2 || handler.args[0][0] != 5 || handler.args[0][1] != "upsert" {
+		t.Errorf("wrong args: %v", handler.args[0])
+	}
+
+	handler.err = sql.ErrNoRows
+
+	res, err = db.Upsert(qc.ChangeColumnName("Id"))
+	if err != nil || res != 0 {
+		t.Errorf("skipped row must not be an error: %d %v", res, err)
+	}
+
+	if _, err := db.Upsert(qc.ChangeOnConflict(sqlstrings.OnConflict{})); !errors.Is(err, sqlstrings.ErrNoConflictColumns) {
+		t.Errorf("expected ErrNoConflictColumns, got %v", err)
+	}
+}